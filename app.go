@@ -3,9 +3,12 @@ package main
 import (
 	"context"
 	"log/slog"
-	"os"
 	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/diagnostics"
+	"phaas-localservices-ui/dockerclient"
+	"phaas-localservices-ui/history"
 	"phaas-localservices-ui/mage"
+	"phaas-localservices-ui/notify"
 	"phaas-localservices-ui/repo"
 	repobrowser "phaas-localservices-ui/repo_browser"
 	"phaas-localservices-ui/scheduler"
@@ -17,22 +20,39 @@ type App struct {
 
 	jobScheduler *scheduler.Scheduler
 	appSettings  *app.Settings
+	history      *history.Store
 	repoFactory  *repo.Factory
 	repoBrowser  *repobrowser.RepoBrowser
+	notifier     *notify.Notifier
+	logWriter    *diagnostics.RotatingWriter
+	diagnostics  *diagnostics.Diagnostics
+	docker       *dockerclient.Monitor
 }
 
 // NewApp creates a new App application struct
-func NewApp() *App {
+func NewApp(logWriter *diagnostics.RotatingWriter) *App {
 	jobScheduler := scheduler.New()
-	appSettings := &app.Settings{}
-	repoFactory := repo.NewFactory(appSettings, jobScheduler)
+	appSettings := app.NewSettings()
+	historyStore := history.NewStore()
+	dockerMonitor := dockerclient.NewMonitor()
+	repoFactory := repo.NewFactory(appSettings, jobScheduler, historyStore, dockerMonitor)
+	notifier := notify.NewNotifier(appSettings, notify.NewDesktopSink())
+	repoFactory.AddTransitionListener(notifier.HandleTransition)
 
-	return &App{
+	a := &App{
 		jobScheduler: jobScheduler,
 		appSettings:  appSettings,
+		history:      historyStore,
 		repoFactory:  repoFactory,
-		repoBrowser:  repobrowser.NewRepoBrowser(appSettings, jobScheduler, repoFactory),
+		notifier:     notifier,
+		logWriter:    logWriter,
+		diagnostics:  diagnostics.NewDiagnostics(logWriter),
+		docker:       dockerMonitor,
 	}
+	// registered before the repo browser's listener so the shell is ready before repos are rebuilt
+	app.AddChangeListener(appSettings, a.onSettingsChanged)
+	a.repoBrowser = repobrowser.NewRepoBrowser(appSettings, jobScheduler, repoFactory, historyStore, dockerMonitor)
+	return a
 }
 
 // startup is called when the app starts. The context is saved
@@ -40,23 +60,82 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
+	// settings problems put the app in degraded mode instead of exiting, so they can be fixed from the settings page
 	err := a.appSettings.Startup(ctx)
 	if err != nil {
 		slog.With(slog.Any("error", err)).ErrorContext(ctx, "failed to load app settings")
-		os.Exit(1)
 	}
-	err = mage.Init(ctx, a.appSettings)
+	a.applyLogSettings()
+	a.applyDockerSettings()
+	a.diagnostics.Startup(ctx)
+	err = app.WatchSettingsFile(a.appSettings, a.jobScheduler)
 	if err != nil {
-		slog.With(slog.Any("error", err)).ErrorContext(ctx, "Failed to init shell")
-		os.Exit(1)
+		slog.With(slog.Any("error", err)).ErrorContext(ctx, "Failed to watch settings file")
 	}
+	err = dockerclient.StartMonitor(a.docker, ctx, a.jobScheduler)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(ctx, "Failed to start docker health monitor")
+	}
+	a.notifier.Startup(ctx)
+	err = a.history.Startup(ctx)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(ctx, "Failed to start history store")
+	}
+	a.initShell()
 	a.repoBrowser.Startup(ctx)
 	a.jobScheduler.Start(ctx)
 }
 
+// shutdown is called when the app quits, after the frontend is gone
+func (a *App) shutdown(ctx context.Context) {
+	repobrowser.Shutdown(a.repoBrowser)
+}
+
+func (a *App) initShell() {
+	err := mage.Init(a.ctx, a.appSettings)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(a.ctx, "Failed to init shell")
+	}
+	app.ReportShellInitFailure(a.appSettings, err)
+}
+
+func (a *App) applyLogSettings() {
+	level, err := diagnostics.ParseLevel(a.appSettings.LogLevel)
+	if err != nil {
+		slog.With(slog.Any("error", err)).WarnContext(a.ctx, "Invalid log level, using info")
+	}
+	diagnostics.LogLevel.Set(level)
+	a.logWriter.SetLimits(diagnostics.RotationLimits{
+		MaxSizeMB:  a.appSettings.AppLogs.MaxSizeMB,
+		MaxAgeDays: a.appSettings.AppLogs.MaxAgeDays,
+		MaxBackups: a.appSettings.AppLogs.MaxBackups,
+	})
+}
+
+func (a *App) applyDockerSettings() {
+	err := dockerclient.Configure(a.appSettings.Docker.Endpoint())
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(a.ctx, "Invalid docker endpoint")
+	}
+}
+
+func (a *App) onSettingsChanged(change app.SettingsChange) {
+	if change.Has(app.FieldShellExecutablePath, app.FieldShellInitFilePath) {
+		a.initShell()
+	}
+	if change.Has(app.FieldLogLevel, app.FieldAppLogs) {
+		a.applyLogSettings()
+	}
+	if change.Has(app.FieldDocker) {
+		a.applyDockerSettings()
+	}
+}
+
 func (a *App) getExposedInterfaces() []any {
 	return []any{
 		a.repoBrowser,
 		a.appSettings,
+		a.diagnostics,
+		a.docker,
 	}
 }
@@ -0,0 +1,250 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const BundleVersion = 1
+
+// SettingsBundle is the portable part of the settings that a team can share: everything except machine-specific
+// paths and secret values. Paths and values under the user's home, repos or data dir are written with ${HOME},
+// ${REPOS_DIR} and ${DATA_DIR} placeholders that are expanded again on import.
+type SettingsBundle struct {
+	BundleVersion int                     `json:"bundleVersion"`
+	EnvParams     []EnvParam              `json:"envParams"`
+	EnvPresets    []EnvPreset             `json:"envPresets"`
+	Repos         map[string]RepoSettings `json:"repos"`
+	Notifications NotificationSettings    `json:"notifications"`
+}
+
+type BundleImportReport struct {
+	EnvParams EnvImportReport `json:"envParams"`
+	// EnvPresets and Repos list the presets and repo settings that were added or replaced by the bundle
+	EnvPresets []string `json:"envPresets"`
+	Repos      []string `json:"repos"`
+	// MissingSecrets lists secret params from the bundle that have no value stored on this machine yet, e.g.
+	// "envParams.API_KEY" or "repos.phaas-foo-api.envParams.DB_PASSWORD"
+	MissingSecrets []string `json:"missingSecrets"`
+}
+
+type placeholder struct {
+	name string
+	dir  string
+}
+
+// placeholders returns the placeholders that can be used on this machine, most specific first so that e.g. a repos
+// dir inside the home dir is written as ${REPOS_DIR} rather than ${HOME}/...
+func (this *Settings) placeholders() []placeholder {
+	list := []placeholder{
+		{"REPOS_DIR", strings.TrimSuffix(this.ReposDirPath, "/")},
+		{"DATA_DIR", strings.TrimSuffix(this.DataDirPath, "/")},
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		list = append(list, placeholder{"HOME", strings.TrimSuffix(home, "/")})
+	}
+	list = slices.DeleteFunc(list, func(p placeholder) bool {
+		return p.dir == ""
+	})
+	slices.SortStableFunc(list, func(a, b placeholder) int {
+		return len(b.dir) - len(a.dir)
+	})
+	return list
+}
+
+// isPathChar reports whether c can be part of a path segment
+func isPathChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-'
+}
+
+// collapsePlaceholders replaces the placeholder dirs in value. A dir is only replaced where it is a whole path, so
+// /home/bob doesn't match inside /home/bobby or /mnt/home/bob.
+func collapsePlaceholders(value string, placeholders []placeholder) string {
+	for _, p := range placeholders {
+		var builder strings.Builder
+		rest := value
+		for {
+			idx := strings.Index(rest, p.dir)
+			if idx < 0 {
+				builder.WriteString(rest)
+				break
+			}
+			end := idx + len(p.dir)
+			startsPath := idx == 0 || (rest[idx-1] != '/' && !isPathChar(rest[idx-1]))
+			endsPath := end == len(rest) || rest[end] == '/' || !isPathChar(rest[end])
+			builder.WriteString(rest[:idx])
+			if startsPath && endsPath {
+				builder.WriteString("${" + p.name + "}")
+			} else {
+				builder.WriteString(p.dir)
+			}
+			rest = rest[end:]
+		}
+		value = builder.String()
+	}
+	return value
+}
+
+var placeholderRegex = regexp.MustCompile(`\$\{([A-Z_]+)\}`)
+
+// expandPlaceholders expands known placeholders and leaves anything else untouched
+func expandPlaceholders(value string, placeholders []placeholder) string {
+	return placeholderRegex.ReplaceAllStringFunc(value, func(match string) string {
+		name := placeholderRegex.FindStringSubmatch(match)[1]
+		for _, p := range placeholders {
+			if p.name == name {
+				return p.dir
+			}
+		}
+		return match
+	})
+}
+
+func mapEnvParams(params []EnvParam, fn func(string) string) []EnvParam {
+	if params == nil {
+		return nil
+	}
+	mapped := make([]EnvParam, len(params))
+	for i, param := range params {
+		mapped[i] = param
+		if param.Secret {
+			mapped[i].Value = ""
+			continue
+		}
+		mapped[i].Value = fn(param.Value)
+	}
+	return mapped
+}
+
+func (this *Settings) ExportSettingsBundle(path string) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	placeholders := this.placeholders()
+	collapse := func(value string) string {
+		return collapsePlaceholders(value, placeholders)
+	}
+	bundle := SettingsBundle{
+		BundleVersion: BundleVersion,
+		EnvParams:     mapEnvParams(this.EnvParams, collapse),
+		EnvPresets:    make([]EnvPreset, 0, len(this.EnvPresets)),
+		Repos:         map[string]RepoSettings{},
+		Notifications: this.Notifications,
+	}
+	for _, preset := range this.EnvPresets {
+		bundle.EnvPresets = append(bundle.EnvPresets, EnvPreset{
+			Name:      preset.Name,
+			EnvParams: mapEnvParams(preset.EnvParams, collapse),
+		})
+	}
+	for repoName, repoSettings := range this.Repos {
+		repoSettings.EnvParams = mapEnvParams(repoSettings.EnvParams, collapse)
+		repoSettings.EnvFilePath = collapse(repoSettings.EnvFilePath)
+		bundle.Repos[repoName] = repoSettings
+	}
+
+	bundleJSON, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings bundle: %w", err)
+	}
+	err = os.WriteFile(path, bundleJSON, 0o644)
+	if err != nil {
+		slog.With(slog.Any("error", err), slog.String("path", path)).ErrorContext(this.ctx, "Failed to export settings bundle")
+		return fmt.Errorf("failed to write settings bundle: %w", err)
+	}
+	return nil
+}
+
+// ImportSettingsBundle merges a settings bundle into the current settings. Env params are merged by key like
+// ImportEnvFile, while presets and per-repo settings in the bundle replace the ones with the same name.
+func (this *Settings) ImportSettingsBundle(path string) (BundleImportReport, error) {
+	bundleJSON, err := os.ReadFile(path)
+	if err != nil {
+		return BundleImportReport{}, fmt.Errorf("failed to read settings bundle: %w", err)
+	}
+	var bundle SettingsBundle
+	err = json.Unmarshal(bundleJSON, &bundle)
+	if err != nil {
+		return BundleImportReport{}, fmt.Errorf("failed to unmarshal settings bundle: %w", err)
+	}
+	if bundle.BundleVersion > BundleVersion {
+		return BundleImportReport{}, fmt.Errorf("settings bundle version %d is newer than supported version %d", bundle.BundleVersion, BundleVersion)
+	}
+
+	report := BundleImportReport{
+		EnvPresets:     []string{},
+		Repos:          []string{},
+		MissingSecrets: []string{},
+	}
+	err = this.update(func(updated *Settings) error {
+		placeholders := this.placeholders()
+		expand := func(value string) string {
+			return expandPlaceholders(value, placeholders)
+		}
+		existingSecrets := this.secretRefs()
+		// secret params arrive without values. The value already stored here is kept, a plaintext param with the same
+		// key keeps its value as the secret's value, and otherwise the secret is reported as missing.
+		resolveSecrets := func(scope string, field string, params []EnvParam, existing []EnvParam) []EnvParam {
+			for i, param := range params {
+				if !param.Secret {
+					continue
+				}
+				if existingSecrets[secretRef(scope, param.Key)] {
+					params[i].Value = RedactedSecretValue
+					continue
+				}
+				idx := slices.IndexFunc(existing, func(candidate EnvParam) bool {
+					return !candidate.Secret && candidate.Value != "" && strings.EqualFold(candidate.Key, param.Key)
+				})
+				if idx >= 0 {
+					params[i].Value = existing[idx].Value
+				} else {
+					report.MissingSecrets = append(report.MissingSecrets, field+"."+param.Key)
+				}
+			}
+			return params
+		}
+
+		var envReport EnvImportReport
+		updated.EnvParams, envReport = mergeEnvParams(updated.EnvParams, resolveSecrets(globalSecretScope, FieldEnvParams, mapEnvParams(bundle.EnvParams, expand), updated.EnvParams))
+		report.EnvParams = envReport
+
+		for _, preset := range bundle.EnvPresets {
+			idx := slices.IndexFunc(updated.EnvPresets, func(existing EnvPreset) bool { return existing.Name == preset.Name })
+			var existingParams []EnvParam
+			if idx >= 0 {
+				existingParams = updated.EnvPresets[idx].EnvParams
+			}
+			preset.EnvParams = resolveSecrets(presetSecretScope(preset.Name), FieldEnvPresets+"."+preset.Name, mapEnvParams(preset.EnvParams, expand), existingParams)
+			if idx < 0 {
+				updated.EnvPresets = append(updated.EnvPresets, preset)
+			} else {
+				updated.EnvPresets[idx] = preset
+			}
+			report.EnvPresets = append(report.EnvPresets, preset.Name)
+		}
+
+		if updated.Repos == nil {
+			updated.Repos = map[string]RepoSettings{}
+		}
+		for _, repoName := range slices.Sorted(maps.Keys(bundle.Repos)) {
+			repoSettings := bundle.Repos[repoName]
+			repoSettings.EnvParams = resolveSecrets(repoSecretScope(repoName), FieldRepos+"."+repoName+".envParams", mapEnvParams(repoSettings.EnvParams, expand), updated.Repos[repoName].EnvParams)
+			repoSettings.EnvFilePath = expand(repoSettings.EnvFilePath)
+			updated.Repos[repoName] = repoSettings
+			report.Repos = append(report.Repos, repoName)
+		}
+		updated.Notifications = bundle.Notifications
+		return nil
+	})
+	if err != nil {
+		slog.With(slog.Any("error", err), slog.String("path", path)).ErrorContext(this.ctx, "Failed to import settings bundle")
+		return BundleImportReport{}, err
+	}
+	return report, nil
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var testPlaceholders = []placeholder{
+	{"REPOS_DIR", "/home/a/repos"},
+	{"DATA_DIR", "/srv/data"},
+	{"HOME", "/home/a"},
+}
+
+func TestCollapsePlaceholders(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"/home/a", "${HOME}"},
+		{"/home/a/.env", "${HOME}/.env"},
+		{"/home/ab/.env", "/home/ab/.env"},
+		{"/home/a.bak", "/home/a.bak"},
+		{"/mnt/home/a/.env", "/mnt/home/a/.env"},
+		{"/home/a/repos/phaas-foo-api/.env", "${REPOS_DIR}/phaas-foo-api/.env"},
+		{"/home/a/reposx", "${HOME}/reposx"},
+		{"/srv/data/dump.sql", "${DATA_DIR}/dump.sql"},
+		{"/home/a/bin:/home/a", "${HOME}/bin:${HOME}"},
+		{"--config=/home/a/config.yaml", "--config=${HOME}/config.yaml"},
+		{"plain value", "plain value"},
+	}
+	for _, test := range tests {
+		got := collapsePlaceholders(test.value, testPlaceholders)
+		if got != test.want {
+			t.Errorf("collapsePlaceholders(%q) = %q, want %q", test.value, got, test.want)
+		}
+		if expanded := expandPlaceholders(got, testPlaceholders); expanded != test.value {
+			t.Errorf("expandPlaceholders(%q) = %q, want the original %q", got, expanded, test.value)
+		}
+	}
+}
+
+func TestExpandPlaceholders(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"${HOME}/.env", "/home/a/.env"},
+		{"${REPOS_DIR}/${DATA_DIR}", "/home/a/repos//srv/data"},
+		{"${UNKNOWN}/.env", "${UNKNOWN}/.env"},
+		{"$HOME/.env", "$HOME/.env"},
+	}
+	for _, test := range tests {
+		if got := expandPlaceholders(test.value, testPlaceholders); got != test.want {
+			t.Errorf("expandPlaceholders(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
+
+func TestPlaceholdersMostSpecificFirst(t *testing.T) {
+	t.Setenv("HOME", "/home/a/")
+	settings := &Settings{ReposDirPath: "/home/a/repos/", DataDirPath: "/home/a/repos/.data"}
+
+	got := collapsePlaceholders("/home/a/repos/.data/dumps:/home/a/repos/x:/home/a/x", settings.placeholders())
+	if want := "${DATA_DIR}/dumps:${REPOS_DIR}/x:${HOME}/x"; got != want {
+		t.Errorf("collapsePlaceholders() = %q, want %q", got, want)
+	}
+}
+
+func writeBundle(t *testing.T, bundle SettingsBundle) string {
+	t.Helper()
+	bundleJSON, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(path, bundleJSON, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportSettingsBundleKeepsExistingValuesOfSecrets(t *testing.T) {
+	recordEvents(t)
+	settings, _ := watchedSettings(t)
+	store := settings.secrets.(*memoryStore)
+	store.values[secretRef(globalSecretScope, "STORED")] = "kept"
+	settings.EnvParams = []EnvParam{
+		{Key: "STORED", Enabled: true, Secret: true},
+		{Key: "API_KEY", Value: "plaintext", Enabled: true},
+	}
+	settings.Repos = map[string]RepoSettings{
+		"phaas-foo-api": {EnvParams: []EnvParam{{Key: "DB_PASSWORD", Value: "hunter2", Enabled: true}}},
+	}
+	path := writeBundle(t, SettingsBundle{
+		BundleVersion: BundleVersion,
+		EnvParams: []EnvParam{
+			{Key: "STORED", Enabled: true, Secret: true},
+			{Key: "API_KEY", Enabled: true, Secret: true},
+			{Key: "NEW_SECRET", Enabled: true, Secret: true},
+		},
+		Repos: map[string]RepoSettings{
+			"phaas-foo-api": {EnvParams: []EnvParam{{Key: "DB_PASSWORD", Enabled: true, Secret: true}}},
+		},
+	})
+
+	report, err := settings.ImportSettingsBundle(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"envParams.NEW_SECRET"}; !slices.Equal(report.MissingSecrets, want) {
+		t.Errorf("MissingSecrets = %v, want %v", report.MissingSecrets, want)
+	}
+	if len(report.EnvParams.Conflicts) > 0 {
+		t.Errorf("Conflicts = %v, want none since no value changed", report.EnvParams.Conflicts)
+	}
+	want := map[string]string{
+		secretRef(globalSecretScope, "STORED"):                     "kept",
+		secretRef(globalSecretScope, "API_KEY"):                    "plaintext",
+		secretRef(repoSecretScope("phaas-foo-api"), "DB_PASSWORD"): "hunter2",
+	}
+	for ref, value := range want {
+		if store.values[ref] != value {
+			t.Errorf("secret %s = %q, want %q", ref, store.values[ref], value)
+		}
+	}
+	for _, param := range settings.EnvParams {
+		if !param.Secret || param.Value != "" {
+			t.Errorf("param %+v should be a secret with its value in the store", param)
+		}
+	}
+}
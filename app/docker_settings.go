@@ -0,0 +1,53 @@
+package app
+
+import (
+	"phaas-localservices-ui/dockerclient"
+	"slices"
+)
+
+// DockerSettings picks the docker daemon to use. Host takes a socket path or URL and Context the name of a docker CLI
+// context, at most one of them may be set. With neither the daemon from DOCKER_HOST or the CLI's current context is
+// used.
+type DockerSettings struct {
+	Host    string `json:"host"`
+	Context string `json:"context"`
+}
+
+func (this DockerSettings) Endpoint() dockerclient.Endpoint {
+	return dockerclient.Endpoint{Host: this.Host, Context: this.Context}
+}
+
+func (this DockerSettings) validate(v *validator) {
+	if this.Host != "" && this.Context != "" {
+		v.add(FieldDocker, "set either a host or a context, not both")
+		return
+	}
+	if this.Host != "" {
+		if _, err := dockerclient.NormalizeHost(this.Host); err != nil {
+			v.add(FieldDocker+".host", "expected a socket path or a unix://, tcp://, http(s):// or npipe:// URL")
+		}
+	}
+	if this.Context != "" {
+		contexts, err := dockerclient.ListContexts()
+		if err == nil && !slices.ContainsFunc(contexts, func(c dockerclient.DockerContext) bool {
+			return c.Name == this.Context
+		}) {
+			v.add(FieldDocker+".context", "no docker context is named '%s'", this.Context)
+		}
+	}
+}
+
+// TestDockerConnection connects to the daemon the docker settings point at, without applying them
+func (this *Settings) TestDockerConnection(docker DockerSettings) (dockerclient.ConnectionInfo, error) {
+	v := &validator{}
+	docker.validate(v)
+	if err := asValidationError(v.errors); err != nil {
+		return dockerclient.ConnectionInfo{}, err
+	}
+	return dockerclient.TestConnection(this.ctx, docker.Endpoint())
+}
+
+// ListDockerContexts lists the docker CLI contexts that can be picked in the docker settings
+func (this *Settings) ListDockerContexts() ([]dockerclient.DockerContext, error) {
+	return dockerclient.ListContexts()
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type dotenvEntry struct {
+	Key   string
+	Value string
+}
+
+var envKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseDotenv parses dotenv syntax: blank lines and # comments are skipped, keys may be prefixed with "export",
+// single-quoted values are literal, double-quoted values support \n, \t, \" and \\ escapes, both kinds of quoted
+// value may span multiple lines, and unquoted values end at an inline " #" comment.
+func parseDotenv(contents string) ([]dotenvEntry, error) {
+	contents = strings.ReplaceAll(contents, "\r\n", "\n")
+	lines := strings.Split(contents, "\n")
+	entries := make([]dotenvEntry, 0)
+	for lineIdx := 0; lineIdx < len(lines); lineIdx++ {
+		lineNumber := lineIdx + 1
+		line := strings.TrimSpace(lines[lineIdx])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, rest, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		if !envKeyRegex.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid key '%s'", lineNumber, key)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+
+		if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+			value, _, _ := strings.Cut(rest, " #")
+			entries = append(entries, dotenvEntry{Key: key, Value: strings.TrimSpace(value)})
+			continue
+		}
+
+		quote := rest[0]
+		raw := rest[1:]
+		for {
+			end := closingQuote(raw, quote)
+			if end >= 0 {
+				trailing := strings.TrimSpace(raw[end+1:])
+				if trailing != "" && !strings.HasPrefix(trailing, "#") {
+					return nil, fmt.Errorf("line %d: unexpected characters after closing quote", lineNumber)
+				}
+				raw = raw[:end]
+				break
+			}
+			lineIdx++
+			if lineIdx >= len(lines) {
+				return nil, fmt.Errorf("line %d: unterminated quoted value for '%s'", lineNumber, key)
+			}
+			raw += "\n" + lines[lineIdx]
+		}
+		value := raw
+		if quote == '"' {
+			value = unescapeDoubleQuoted(raw)
+		}
+		entries = append(entries, dotenvEntry{Key: key, Value: value})
+	}
+	return entries, nil
+}
+
+// closingQuote returns the index of the unescaped closing quote in s, or -1
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDoubleQuoted(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			builder.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		default:
+			builder.WriteByte(s[i])
+		}
+	}
+	return builder.String()
+}
+
+var dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+func formatDotenvLine(key string, value string) string {
+	return fmt.Sprintf(`%s="%s"`, key, dotenvEscaper.Replace(value))
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []dotenvEntry
+	}{
+		{
+			name:     "comments and blank lines",
+			contents: "# comment\n\nA=1\n   # indented comment\nB=2\n",
+			want:     []dotenvEntry{{"A", "1"}, {"B", "2"}},
+		},
+		{
+			name:     "export prefix and whitespace",
+			contents: "export A=1\n  B = two  \n",
+			want:     []dotenvEntry{{"A", "1"}, {"B", "two"}},
+		},
+		{
+			name:     "empty value",
+			contents: "A=\nB=\"\"",
+			want:     []dotenvEntry{{"A", ""}, {"B", ""}},
+		},
+		{
+			name:     "inline comment on unquoted value",
+			contents: "A=value # comment\nB=pass#word",
+			want:     []dotenvEntry{{"A", "value"}, {"B", "pass#word"}},
+		},
+		{
+			name:     "value containing equals",
+			contents: "URL=postgres://u:p@host/db?sslmode=disable",
+			want:     []dotenvEntry{{"URL", "postgres://u:p@host/db?sslmode=disable"}},
+		},
+		{
+			name:     "single quotes are literal",
+			contents: `A='a\nb # not a comment'`,
+			want:     []dotenvEntry{{"A", `a\nb # not a comment`}},
+		},
+		{
+			name:     "double quote escapes",
+			contents: `A="line1\nline2\t\"quoted\" back\\slash"`,
+			want:     []dotenvEntry{{"A", "line1\nline2\t\"quoted\" back\\slash"}},
+		},
+		{
+			name:     "comment after closing quote",
+			contents: `A="value" # comment`,
+			want:     []dotenvEntry{{"A", "value"}},
+		},
+		{
+			name:     "multiline quoted value",
+			contents: "A=\"first\nsecond\"\nB='x\ny'\nC=3",
+			want:     []dotenvEntry{{"A", "first\nsecond"}, {"B", "x\ny"}, {"C", "3"}},
+		},
+		{
+			name:     "windows line endings",
+			contents: "A=1\r\nB=2\r\n",
+			want:     []dotenvEntry{{"A", "1"}, {"B", "2"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseDotenv(test.contents)
+			if err != nil {
+				t.Fatalf("parseDotenv() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseDotenv() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"missing equals", "A=1\nJUSTAKEY"},
+		{"invalid key", "1A=1"},
+		{"key with dash", "MY-KEY=1"},
+		{"unterminated quote", "A=\"never closed\nB=2"},
+		{"text after closing quote", `A="value" trailing`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseDotenv(test.contents)
+			if err == nil {
+				t.Error("parseDotenv() expected an error")
+			}
+		})
+	}
+}
+
+func TestFormatDotenvLineRoundTrips(t *testing.T) {
+	values := []string{"", "plain", "with space", "quote \" inside", `back\slash`, "multi\nline\ttab\r", "# hash"}
+	for _, value := range values {
+		line := formatDotenvLine("KEY", value)
+		entries, err := parseDotenv(line)
+		if err != nil {
+			t.Fatalf("parseDotenv(%q) error = %v", line, err)
+		}
+		if len(entries) != 1 || entries[0].Value != value {
+			t.Errorf("round trip of %q gave %q", value, entries)
+		}
+	}
+}
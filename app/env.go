@@ -0,0 +1,147 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type EnvLayer string
+
+// Env params are resolved in layers, with each layer overriding the ones before it:
+// global settings, then the active env preset, then the repo's own settings, then one-off params given for a
+// single start. A repo's env file, when it has one, is read on every start and sits just below the repo's own params.
+const (
+	EnvLayerGlobal  EnvLayer = "global"
+	EnvLayerPreset  EnvLayer = "preset"
+	EnvLayerEnvFile EnvLayer = "env-file"
+	EnvLayerRepo    EnvLayer = "repo"
+	EnvLayerOneOff  EnvLayer = "one-off"
+)
+
+var AllEnvLayers = []struct {
+	Value  EnvLayer
+	TSName string
+}{
+	{EnvLayerGlobal, "global"},
+	{EnvLayerPreset, "preset"},
+	{EnvLayerEnvFile, "envFile"},
+	{EnvLayerRepo, "repo"},
+	{EnvLayerOneOff, "oneOff"},
+}
+
+type ResolvedEnvParam struct {
+	Key string `json:"key"`
+	// Value is redacted for secret params, see ResolveEnvWithSecrets
+	Value  string   `json:"value"`
+	Secret bool     `json:"secret"`
+	Layer  EnvLayer `json:"layer"`
+	// OverriddenLayers lists the lower layers that also set this key, in the order they were overridden
+	OverriddenLayers []EnvLayer `json:"overriddenLayers"`
+
+	secretRef string
+}
+
+type envLayerParams struct {
+	layer EnvLayer
+	// scope namespaces the layer's secrets in the secret store, layers without a scope hold their values directly
+	scope  string
+	params []EnvParam
+}
+
+// ResolveEnv returns the enabled env params that apply to a start of the repo, sorted by key. Keys are compared
+// case-insensitively and returned upper-cased, matching how they are passed to mage.
+func (this *Settings) ResolveEnv(repoName string, oneOff []EnvParam) ([]ResolvedEnvParam, error) {
+	return this.resolveEnv(repoName, this.ActiveEnvPreset, oneOff)
+}
+
+// ResolveEnvForPreset returns the env params a start of the repo would use if the named preset were active
+func (this *Settings) ResolveEnvForPreset(repoName string, presetName string) ([]ResolvedEnvParam, error) {
+	return this.resolveEnv(repoName, presetName, nil)
+}
+
+func (this *Settings) resolveEnv(repoName string, presetName string, oneOff []EnvParam) ([]ResolvedEnvParam, error) {
+	var presetParams []EnvParam
+	if preset, found := this.getEnvPreset(presetName); found {
+		presetParams = preset.EnvParams
+	}
+	repoSettings := this.repoSettings(repoName)
+	var envFileParams []EnvParam
+	if repoSettings.EnvFilePath != "" {
+		var err error
+		envFileParams, err = readEnvFile(repoSettings.EnvFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env file for repo '%s': %w", repoName, err)
+		}
+	}
+	layers := []envLayerParams{
+		{EnvLayerGlobal, globalSecretScope, this.EnvParams},
+		{EnvLayerPreset, presetSecretScope(presetName), presetParams},
+		{EnvLayerEnvFile, "", envFileParams},
+		{EnvLayerRepo, repoSecretScope(repoName), repoSettings.EnvParams},
+		{EnvLayerOneOff, "", oneOff},
+	}
+
+	resolved := map[string]*ResolvedEnvParam{}
+	for _, layer := range layers {
+		for _, param := range layer.params {
+			if !param.Enabled || param.Key == "" {
+				continue
+			}
+			key := strings.ToUpper(param.Key)
+			value := param.Value
+			ref := ""
+			if param.Secret && layer.scope != "" {
+				value = RedactedSecretValue
+				ref = secretRef(layer.scope, key)
+			}
+			existing, found := resolved[key]
+			if !found {
+				resolved[key] = &ResolvedEnvParam{
+					Key:              key,
+					Value:            value,
+					Secret:           param.Secret,
+					Layer:            layer.layer,
+					OverriddenLayers: []EnvLayer{},
+					secretRef:        ref,
+				}
+				continue
+			}
+			if existing.Layer != layer.layer {
+				existing.OverriddenLayers = append(existing.OverriddenLayers, existing.Layer)
+			}
+			existing.Value = value
+			existing.Secret = param.Secret
+			existing.Layer = layer.layer
+			existing.secretRef = ref
+		}
+	}
+
+	list := make([]ResolvedEnvParam, 0, len(resolved))
+	for _, param := range resolved {
+		list = append(list, *param)
+	}
+	slices.SortFunc(list, func(a, b ResolvedEnvParam) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return list, nil
+}
+
+// ResolveEnvWithSecrets resolves the env like ResolveEnv but with secret values read from the secret store
+func ResolveEnvWithSecrets(settings *Settings, repoName string, oneOff []EnvParam) ([]ResolvedEnvParam, error) {
+	resolved, err := settings.ResolveEnv(repoName, oneOff)
+	if err != nil {
+		return nil, err
+	}
+	for i, param := range resolved {
+		if param.secretRef == "" {
+			continue
+		}
+		value, err := settings.getSecret(param.secretRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secret env param '%s': %w", param.Key, err)
+		}
+		resolved[i].Value = value
+	}
+	return resolved, nil
+}
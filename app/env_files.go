@@ -0,0 +1,147 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+// overridePrefix is added to every env param key when it is passed to mage, so it is stripped from imported keys
+const overridePrefix = "PHAAS_OVERRIDE_"
+
+type EnvImportConflict struct {
+	Key string `json:"key"`
+	// PreviousValue and ImportedValue are redacted when the existing param is secret
+	PreviousValue string `json:"previousValue"`
+	ImportedValue string `json:"importedValue"`
+}
+
+type EnvImportReport struct {
+	Added     []string            `json:"added"`
+	Unchanged []string            `json:"unchanged"`
+	Conflicts []EnvImportConflict `json:"conflicts"`
+}
+
+func readEnvFile(path string) ([]EnvParam, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	entries, err := parseDotenv(string(contents))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env file '%s': %w", path, err)
+	}
+	params := make([]EnvParam, 0, len(entries))
+	for _, entry := range entries {
+		params = append(params, EnvParam{
+			Key:     strings.TrimPrefix(entry.Key, overridePrefix),
+			Value:   entry.Value,
+			Enabled: true,
+		})
+	}
+	return params, nil
+}
+
+// ImportEnvFile merges the params of a dotenv file into the global env params. Imported params are enabled and
+// their values replace existing ones; every replaced value is reported as a conflict.
+func (this *Settings) ImportEnvFile(path string) (EnvImportReport, error) {
+	imported, err := readEnvFile(path)
+	if err != nil {
+		slog.With(slog.Any("error", err), slog.String("path", path)).ErrorContext(this.ctx, "Failed to import env file")
+		return EnvImportReport{}, err
+	}
+
+	var report EnvImportReport
+	err = this.update(func(updated *Settings) error {
+		updated.EnvParams, report = mergeEnvParams(updated.EnvParams, imported)
+		return nil
+	})
+	if err != nil {
+		return EnvImportReport{}, err
+	}
+	return report, nil
+}
+
+// mergeEnvParams merges imported into existing by case-insensitive key, with imported values and enabled flags
+// winning. A param stays secret if either side is secret, and an existing secret keeps its value when the import has
+// none.
+func mergeEnvParams(existing []EnvParam, imported []EnvParam) ([]EnvParam, EnvImportReport) {
+	report := EnvImportReport{
+		Added:     []string{},
+		Unchanged: []string{},
+		Conflicts: []EnvImportConflict{},
+	}
+	merged := slices.Clone(existing)
+	for _, param := range imported {
+		idx := slices.IndexFunc(merged, func(candidate EnvParam) bool {
+			return strings.EqualFold(candidate.Key, param.Key)
+		})
+		if idx < 0 {
+			merged = append(merged, param)
+			report.Added = append(report.Added, param.Key)
+			continue
+		}
+
+		current := merged[idx]
+		switch {
+		case current.Secret && (param.Value == "" || param.Value == RedactedSecretValue):
+			// exports leave secret values out, so importing one back must keep the stored secret rather than clear it
+			report.Unchanged = append(report.Unchanged, current.Key)
+			merged[idx].Enabled = param.Enabled
+			continue
+		case current.Secret || param.Secret:
+			if param.Value == RedactedSecretValue || (!current.Secret && param.Value == current.Value) {
+				report.Unchanged = append(report.Unchanged, current.Key)
+			} else {
+				// the stored value can't be compared without reading the keyring, so treat every import as a change
+				report.Conflicts = append(report.Conflicts, EnvImportConflict{
+					Key:           current.Key,
+					PreviousValue: RedactedSecretValue,
+					ImportedValue: RedactedSecretValue,
+				})
+			}
+		case current.Value == param.Value:
+			report.Unchanged = append(report.Unchanged, current.Key)
+		default:
+			report.Conflicts = append(report.Conflicts, EnvImportConflict{
+				Key:           current.Key,
+				PreviousValue: current.Value,
+				ImportedValue: param.Value,
+			})
+		}
+		merged[idx].Value = param.Value
+		merged[idx].Enabled = param.Enabled
+		merged[idx].Secret = current.Secret || param.Secret
+	}
+	return merged, report
+}
+
+// ExportEnvFile writes the global env params to a dotenv file. Disabled params are written commented out and secret
+// params are written without their value.
+func (this *Settings) ExportEnvFile(path string) error {
+	var builder strings.Builder
+	builder.WriteString("# Exported from phaas-localservices-manager. Keys are passed to services as " + overridePrefix + "<KEY>.\n")
+	this.mutex.Lock()
+	params := slices.Clone(this.EnvParams)
+	this.mutex.Unlock()
+	for _, param := range params {
+		line := formatDotenvLine(param.Key, param.Value)
+		if param.Secret {
+			builder.WriteString("# secret, value not exported\n")
+			line = param.Key + "="
+		}
+		if !param.Enabled {
+			line = "# " + line
+		}
+		builder.WriteString(line + "\n")
+	}
+
+	err := os.WriteFile(path, []byte(builder.String()), 0o600)
+	if err != nil {
+		slog.With(slog.Any("error", err), slog.String("path", path)).ErrorContext(this.ctx, "Failed to export env file")
+		return fmt.Errorf("failed to export env file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestEnvFileRoundTripKeepsSecrets(t *testing.T) {
+	settings, store := settingsWithSecrets(
+		[]EnvParam{
+			{Key: "DB_PASSWORD", Secret: true, Enabled: true},
+			{Key: "LOG_LEVEL", Value: "debug", Enabled: true},
+		},
+		map[string]string{"global/DB_PASSWORD": "hunter2"},
+	)
+	path := filepath.Join(t.TempDir(), "env")
+	if err := settings.ExportEnvFile(path); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := readEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same steps ImportEnvFile takes through update, without the events it emits afterwards
+	updated := settings.redacted()
+	var report EnvImportReport
+	updated.EnvParams, report = mergeEnvParams(updated.EnvParams, imported)
+	if err := settings.storeSecrets(&updated, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.values["global/DB_PASSWORD"]; got != "hunter2" {
+		t.Errorf("stored secret = %q after the round trip, want it kept", got)
+	}
+	if want := []string{"DB_PASSWORD", "LOG_LEVEL"}; !slices.Equal(report.Unchanged, want) {
+		t.Errorf("unchanged = %v, want %v", report.Unchanged, want)
+	}
+	if len(report.Conflicts) != 0 || len(report.Added) != 0 {
+		t.Errorf("conflicts = %v, added = %v, want none", report.Conflicts, report.Added)
+	}
+}
+
+func TestMergeEnvParamsReplacesSecretWithImportedValue(t *testing.T) {
+	existing := []EnvParam{{Key: "API_TOKEN", Value: RedactedSecretValue, Secret: true, Enabled: true}}
+	merged, report := mergeEnvParams(existing, []EnvParam{{Key: "api_token", Value: "new-token", Enabled: true}})
+
+	if merged[0].Value != "new-token" || !merged[0].Secret {
+		t.Errorf("merged = %+v, want the new value kept secret", merged[0])
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].ImportedValue != RedactedSecretValue {
+		t.Errorf("conflicts = %+v, want one redacted conflict", report.Conflicts)
+	}
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+const EnvPresetChangedEvent = "env-preset-changed"
+
+// EnvPreset is a named group of env params, e.g. to point local services at staging or mocked dependencies.
+// The active preset is layered between the global and the per-repo env params.
+type EnvPreset struct {
+	Name      string     `json:"name"`
+	EnvParams []EnvParam `json:"envParams"`
+}
+
+var ErrEnvPresetNotFound = fmt.Errorf("env preset not found")
+
+func (this *Settings) getEnvPreset(name string) (EnvPreset, bool) {
+	if name == "" {
+		return EnvPreset{}, false
+	}
+	for _, preset := range this.EnvPresets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return EnvPreset{}, false
+}
+
+func (this *Settings) GetActiveEnvPreset() string {
+	return this.ActiveEnvPreset
+}
+
+// SetActiveEnvPreset switches the active env preset. An empty name deactivates presets.
+func (this *Settings) SetActiveEnvPreset(name string) error {
+	this.mutex.Lock()
+	changed, err := this.setActiveEnvPreset(name)
+	this.mutex.Unlock()
+	if err != nil || !changed {
+		return err
+	}
+	this.notifyChanged(SettingsChange{Fields: []string{FieldActiveEnvPreset}})
+	return nil
+}
+
+// setActiveEnvPreset must be called with the mutex held
+func (this *Settings) setActiveEnvPreset(name string) (bool, error) {
+	if name != "" {
+		if _, found := this.getEnvPreset(name); !found {
+			return false, fmt.Errorf("failed to activate env preset '%s': %w", name, ErrEnvPresetNotFound)
+		}
+	}
+	if this.ActiveEnvPreset == name {
+		return false, nil
+	}
+	previous := this.ActiveEnvPreset
+	this.ActiveEnvPreset = name
+
+	err := this.writeToFile()
+	if err != nil {
+		this.ActiveEnvPreset = previous
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to save active env preset")
+		return false, fmt.Errorf("failed to save active env preset: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,33 @@
+package app
+
+const (
+	defaultServiceLogMaxRuns        = 20
+	defaultServiceLogMaxTotalSizeMB = 200
+)
+
+// ServiceLogRetention limits how many per-run service logs are kept for each repo. Zero values use the defaults.
+type ServiceLogRetention struct {
+	MaxRuns        int `json:"maxRuns"`
+	MaxTotalSizeMB int `json:"maxTotalSizeMB"`
+}
+
+// ServiceLogRetentionOf returns the service log retention with defaults filled in, taken under the settings lock
+func ServiceLogRetentionOf(settings *Settings) ServiceLogRetention {
+	settings.mutex.Lock()
+	retention := settings.ServiceLogs
+	settings.mutex.Unlock()
+	if retention.MaxRuns <= 0 {
+		retention.MaxRuns = defaultServiceLogMaxRuns
+	}
+	if retention.MaxTotalSizeMB <= 0 {
+		retention.MaxTotalSizeMB = defaultServiceLogMaxTotalSizeMB
+	}
+	return retention
+}
+
+// AppLogRetention controls rotation of the app's own log file. Zero values use the defaults.
+type AppLogRetention struct {
+	MaxSizeMB  int `json:"maxSizeMB"`
+	MaxAgeDays int `json:"maxAgeDays"`
+	MaxBackups int `json:"maxBackups"`
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// CurrentSchemaVersion is the version of the settings.json layout written by this build. Any change that would
+// make an older file unmarshal incorrectly must bump it and add a migration from the previous version.
+const CurrentSchemaVersion = 1
+
+type settingsDocument = map[string]any
+
+type migration struct {
+	from    int
+	migrate func(doc settingsDocument) error
+}
+
+// migrations upgrade a settings document one version at a time, in order
+var migrations = []migration{
+	{
+		// files written before versioning was added have no schemaVersion but already match the v1 layout
+		from:    0,
+		migrate: func(doc settingsDocument) error { return nil },
+	},
+}
+
+var ErrSettingsFromNewerVersion = errors.New("settings.json was written by a newer version of the app")
+
+func schemaVersion(doc settingsDocument) (int, error) {
+	raw, found := doc["schemaVersion"]
+	if !found || raw == nil {
+		return 0, nil
+	}
+	switch version := raw.(type) {
+	case int:
+		// set by a migration step
+		return version, nil
+	case float64:
+		if version == float64(int(version)) && version >= 0 {
+			return int(version), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid schemaVersion %v", raw)
+}
+
+// migrateSettings upgrades settingsJSON to CurrentSchemaVersion. It returns the original JSON untouched and
+// migrated=false when no migration was needed.
+func migrateSettings(settingsJSON []byte) (migratedJSON []byte, fromVersion int, migrated bool, err error) {
+	var doc settingsDocument
+	err = json.Unmarshal(settingsJSON, &doc)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to unmarshal settings.json: %w", err)
+	}
+	fromVersion, err = schemaVersion(doc)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if fromVersion > CurrentSchemaVersion {
+		return nil, fromVersion, false, fmt.Errorf("%w (schema version %d, this build supports %d)", ErrSettingsFromNewerVersion, fromVersion, CurrentSchemaVersion)
+	}
+	if fromVersion == CurrentSchemaVersion {
+		return settingsJSON, fromVersion, false, nil
+	}
+
+	for _, m := range migrations {
+		version, err := schemaVersion(doc)
+		if err != nil {
+			return nil, fromVersion, false, err
+		}
+		if m.from != version {
+			continue
+		}
+		err = m.migrate(doc)
+		if err != nil {
+			return nil, fromVersion, false, fmt.Errorf("failed to migrate settings from schema version %d: %w", m.from, err)
+		}
+		doc["schemaVersion"] = m.from + 1
+	}
+	version, err := schemaVersion(doc)
+	if err != nil {
+		return nil, fromVersion, false, err
+	}
+	if version != CurrentSchemaVersion {
+		return nil, fromVersion, false, fmt.Errorf("no migration from settings schema version %d", version)
+	}
+
+	migratedJSON, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fromVersion, false, fmt.Errorf("failed to marshal migrated settings: %w", err)
+	}
+	return migratedJSON, fromVersion, true, nil
+}
+
+// backupSettings copies the settings file aside before it is rewritten by a migration
+func (this *Settings) backupSettings(settingsJSON []byte, fromVersion int) error {
+	backupPath := fmt.Sprintf("%s.v%d.%s.bak", this.settingsPath, fromVersion, time.Now().Format("20060102T150405"))
+	err := os.WriteFile(backupPath, settingsJSON, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to back up settings before migrating: %w", err)
+	}
+	slog.With(slog.String("backup", backupPath)).InfoContext(this.ctx, "Backed up settings before migrating")
+	return nil
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMigrateSettingsFromUnversioned(t *testing.T) {
+	migratedJSON, fromVersion, migrated, err := migrateSettings([]byte(`{"reposDirPath":"/repos"}`))
+	if err != nil {
+		t.Fatalf("migrateSettings() error = %v", err)
+	}
+	if !migrated || fromVersion != 0 {
+		t.Errorf("migrateSettings() migrated = %t from %d, want true from 0", migrated, fromVersion)
+	}
+	var doc map[string]any
+	err = json.Unmarshal(migratedJSON, &doc)
+	if err != nil {
+		t.Fatalf("migrated JSON is invalid: %v", err)
+	}
+	if doc["schemaVersion"] != float64(CurrentSchemaVersion) || doc["reposDirPath"] != "/repos" {
+		t.Errorf("unexpected migrated document %v", doc)
+	}
+}
+
+func TestMigrateSettingsCurrentVersionIsUntouched(t *testing.T) {
+	settingsJSON := []byte(`{"schemaVersion":1,"reposDirPath":"/repos"}`)
+	migratedJSON, fromVersion, migrated, err := migrateSettings(settingsJSON)
+	if err != nil {
+		t.Fatalf("migrateSettings() error = %v", err)
+	}
+	if migrated || fromVersion != CurrentSchemaVersion || string(migratedJSON) != string(settingsJSON) {
+		t.Errorf("migrateSettings() = %s, %d, %t, want the input unchanged", migratedJSON, fromVersion, migrated)
+	}
+}
+
+func TestMigrateSettingsErrors(t *testing.T) {
+	_, fromVersion, _, err := migrateSettings([]byte(`{"schemaVersion":99}`))
+	if !errors.Is(err, ErrSettingsFromNewerVersion) || fromVersion != 99 {
+		t.Errorf("expected ErrSettingsFromNewerVersion from version 99, got %v from %d", err, fromVersion)
+	}
+
+	for _, settingsJSON := range []string{`{"schemaVersion":"one"}`, `{"schemaVersion":1.5}`, `{"schemaVersion":-1}`, `not json`} {
+		_, _, _, err = migrateSettings([]byte(settingsJSON))
+		if err == nil || errors.Is(err, ErrSettingsFromNewerVersion) {
+			t.Errorf("migrateSettings(%s) error = %v, want an invalid settings error", settingsJSON, err)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package app
+
+type NotificationSettings struct {
+	Enabled bool `json:"enabled"`
+	// OnReady notifies when a service finishes starting and reports as running
+	OnReady bool `json:"onReady"`
+	// OnStopped notifies when a service stops after Stop was requested
+	OnStopped bool `json:"onStopped"`
+	// OnCrash notifies when a service stops or fails to start without Stop being requested
+	OnCrash bool `json:"onCrash"`
+}
+
+// NotificationSettingsOf returns a copy of the notification settings, taken under the settings lock
+func NotificationSettingsOf(settings *Settings) NotificationSettings {
+	settings.mutex.Lock()
+	defer settings.mutex.Unlock()
+	return settings.Notifications
+}
@@ -0,0 +1,51 @@
+package app
+
+type RestartMode string
+
+const (
+	RestartNever     RestartMode = "never"
+	RestartOnFailure RestartMode = "on-failure"
+	RestartAlways    RestartMode = "always"
+)
+
+var AllRestartModes = []struct {
+	Value  RestartMode
+	TSName string
+}{
+	{RestartNever, "never"},
+	{RestartOnFailure, "onFailure"},
+	{RestartAlways, "always"},
+}
+
+type RestartPolicy struct {
+	Mode RestartMode `json:"mode"`
+	// MaxRetries caps the number of consecutive automatic restarts when Mode is RestartOnFailure. Zero means unlimited.
+	MaxRetries int `json:"maxRetries"`
+}
+
+type RepoSettings struct {
+	RestartPolicy     RestartPolicy `json:"restartPolicy"`
+	MuteNotifications bool          `json:"muteNotifications"`
+	// EnvParams are layered over the global env params when starting this repo
+	EnvParams []EnvParam `json:"envParams"`
+	// EnvFilePath optionally points at a dotenv file that is re-read on every start of this repo
+	EnvFilePath string `json:"envFilePath"`
+}
+
+// RepoSettingsOf returns the settings of the repo, taken under the settings lock
+func RepoSettingsOf(settings *Settings, repoName string) RepoSettings {
+	settings.mutex.Lock()
+	defer settings.mutex.Unlock()
+	return settings.repoSettings(repoName)
+}
+
+func (this *Settings) repoSettings(repoName string) RepoSettings {
+	repoSettings, found := this.Repos[repoName]
+	if !found {
+		return RepoSettings{RestartPolicy: RestartPolicy{Mode: RestartNever}}
+	}
+	if repoSettings.RestartPolicy.Mode == "" {
+		repoSettings.RestartPolicy.Mode = RestartNever
+	}
+	return repoSettings
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"phaas-localservices-ui/secrets"
+	"strings"
+)
+
+// RedactedSecretValue stands in for secret values anywhere they leave the backend. Saving a secret param with this
+// value keeps the stored secret unchanged.
+const RedactedSecretValue = "********"
+
+const globalSecretScope = "global"
+
+func presetSecretScope(presetName string) string {
+	return "preset/" + presetName
+}
+
+func repoSecretScope(repoName string) string {
+	return "repo/" + repoName
+}
+
+func secretRef(scope string, key string) string {
+	return scope + "/" + strings.ToUpper(key)
+}
+
+var ErrSecretsUnavailable = errors.New("secret store not initialized")
+
+func (this *Settings) getSecret(ref string) (string, error) {
+	if this.secrets == nil {
+		return "", ErrSecretsUnavailable
+	}
+	value, err := this.secrets.Get(ref)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return "", fmt.Errorf("no value stored for secret: %w", err)
+	}
+	return value, err
+}
+
+type scopedEnvParams struct {
+	scope  string
+	params []EnvParam
+}
+
+func (this *Settings) scopedEnvParams() []scopedEnvParams {
+	scoped := []scopedEnvParams{{globalSecretScope, this.EnvParams}}
+	for _, preset := range this.EnvPresets {
+		scoped = append(scoped, scopedEnvParams{presetSecretScope(preset.Name), preset.EnvParams})
+	}
+	for repoName, repoSettings := range this.Repos {
+		scoped = append(scoped, scopedEnvParams{repoSecretScope(repoName), repoSettings.EnvParams})
+	}
+	return scoped
+}
+
+func (this *Settings) secretRefs() map[string]bool {
+	refs := map[string]bool{}
+	for _, scoped := range this.scopedEnvParams() {
+		for _, param := range scoped.params {
+			if param.Secret {
+				refs[secretRef(scoped.scope, param.Key)] = true
+			}
+		}
+	}
+	return refs
+}
+
+func (this *Settings) hasPlaintextSecrets() bool {
+	for _, scoped := range this.scopedEnvParams() {
+		for _, param := range scoped.params {
+			if param.Secret && param.Value != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var ErrSecretRenamed = errors.New("the secret was renamed and its stored value can't be told apart, enter the value again")
+
+// storeSecrets moves the values of secret params in incoming into the secret store and blanks them so they are
+// never written to settings.json. Secrets that no longer belong to any param are deleted. Values coming from the
+// frontend are redacted unless the user changed them, so there an empty value means the secret was cleared; in
+// settings loaded from file an empty value just means the secret is already stored.
+//
+// A redacted value on a param that is no longer marked secret turns the stored value into the plaintext value, and on
+// a secret whose key was renamed carries the value stored under the old key over to the new one.
+func (this *Settings) storeSecrets(incoming *Settings, emptyClears bool) error {
+	previousRefs := this.secretRefs()
+	incomingRefs := incoming.secretRefs()
+	unusedRefs := maps.Clone(previousRefs)
+	for _, scoped := range incoming.scopedEnvParams() {
+		for i, param := range scoped.params {
+			ref := secretRef(scoped.scope, param.Key)
+			if !param.Secret {
+				if param.Value == RedactedSecretValue && previousRefs[ref] {
+					value, err := this.getSecret(ref)
+					if err != nil {
+						return fmt.Errorf("failed to read secret env param '%s': %w", param.Key, err)
+					}
+					scoped.params[i].Value = value
+				}
+				continue
+			}
+			delete(unusedRefs, ref)
+			if param.Value == RedactedSecretValue && !previousRefs[ref] {
+				err := this.moveRenamedSecret(scoped.scope, ref, previousRefs, incomingRefs)
+				if err != nil {
+					return fmt.Errorf("failed to store secret env param '%s': %w", param.Key, err)
+				}
+				scoped.params[i].Value = ""
+				continue
+			}
+			if param.Value == RedactedSecretValue || (param.Value == "" && !emptyClears) {
+				scoped.params[i].Value = ""
+				continue
+			}
+			if this.secrets == nil {
+				return ErrSecretsUnavailable
+			}
+			var err error
+			if param.Value == "" {
+				err = this.secrets.Delete(ref)
+			} else {
+				err = this.secrets.Set(ref, param.Value)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to store secret env param '%s': %w", param.Key, err)
+			}
+			scoped.params[i].Value = ""
+		}
+	}
+	for ref := range unusedRefs {
+		if this.secrets == nil {
+			break
+		}
+		err := this.secrets.Delete(ref)
+		if err != nil {
+			slog.With(slog.Any("error", err), slog.String("ref", ref)).WarnContext(this.ctx, "Failed to delete unused secret")
+		}
+	}
+	return nil
+}
+
+// moveRenamedSecret copies the value of a renamed secret to its new ref. The old ref is the one secret of the same
+// scope that incoming no longer has; the old ref itself is deleted with the other unused ones.
+func (this *Settings) moveRenamedSecret(scope string, ref string, previousRefs map[string]bool, incomingRefs map[string]bool) error {
+	var candidates []string
+	for previousRef := range previousRefs {
+		if !incomingRefs[previousRef] && previousRef[:strings.LastIndex(previousRef, "/")] == scope {
+			candidates = append(candidates, previousRef)
+		}
+	}
+	if len(candidates) != 1 {
+		return ErrSecretRenamed
+	}
+	value, err := this.getSecret(candidates[0])
+	if err != nil {
+		return err
+	}
+	return this.secrets.Set(ref, value)
+}
+
+func redactEnvParams(params []EnvParam) []EnvParam {
+	if params == nil {
+		return nil
+	}
+	redacted := make([]EnvParam, len(params))
+	for i, param := range params {
+		redacted[i] = param
+		if param.Secret {
+			redacted[i].Value = RedactedSecretValue
+		}
+	}
+	return redacted
+}
+
+// redacted returns a copy of the settings that is safe to hand to the frontend or write to logs
+func (this *Settings) redacted() Settings {
+	copied := *this
+	copied.EnvParams = redactEnvParams(this.EnvParams)
+	if this.EnvPresets != nil {
+		copied.EnvPresets = make([]EnvPreset, len(this.EnvPresets))
+		for i, preset := range this.EnvPresets {
+			copied.EnvPresets[i] = EnvPreset{Name: preset.Name, EnvParams: redactEnvParams(preset.EnvParams)}
+		}
+	}
+	if this.Repos != nil {
+		copied.Repos = maps.Clone(this.Repos)
+		for repoName, repoSettings := range copied.Repos {
+			repoSettings.EnvParams = redactEnvParams(repoSettings.EnvParams)
+			copied.Repos[repoName] = repoSettings
+		}
+	}
+	return copied
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"errors"
+	"maps"
+	"phaas-localservices-ui/secrets"
+	"testing"
+)
+
+type memoryStore struct {
+	values map[string]string
+}
+
+func (this *memoryStore) Get(ref string) (string, error) {
+	value, found := this.values[ref]
+	if !found {
+		return "", secrets.ErrNotFound
+	}
+	return value, nil
+}
+
+func (this *memoryStore) Set(ref string, value string) error {
+	this.values[ref] = value
+	return nil
+}
+
+func (this *memoryStore) Delete(ref string) error {
+	delete(this.values, ref)
+	return nil
+}
+
+func settingsWithSecrets(params []EnvParam, stored map[string]string) (*Settings, *memoryStore) {
+	store := &memoryStore{values: maps.Clone(stored)}
+	settings := NewSettings()
+	settings.secrets = store
+	settings.EnvParams = params
+	return settings, store
+}
+
+func TestStoreSecretsKeepsUnchangedSecret(t *testing.T) {
+	settings, store := settingsWithSecrets(
+		[]EnvParam{{Key: "DB_PASSWORD", Secret: true}},
+		map[string]string{"global/DB_PASSWORD": "hunter2"},
+	)
+	incoming := &Settings{EnvParams: []EnvParam{{Key: "DB_PASSWORD", Value: RedactedSecretValue, Secret: true}}}
+
+	if err := settings.storeSecrets(incoming, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.values["global/DB_PASSWORD"]; got != "hunter2" {
+		t.Errorf("stored value = %q, want it unchanged", got)
+	}
+	if incoming.EnvParams[0].Value != "" {
+		t.Errorf("param value = %q, want it blanked", incoming.EnvParams[0].Value)
+	}
+}
+
+func TestStoreSecretsCarriesValueOverRename(t *testing.T) {
+	settings, store := settingsWithSecrets(
+		[]EnvParam{{Key: "DB_PASS", Secret: true}, {Key: "API_TOKEN", Secret: true}},
+		map[string]string{"global/DB_PASS": "hunter2", "global/API_TOKEN": "abc"},
+	)
+	incoming := &Settings{EnvParams: []EnvParam{
+		{Key: "DB_PASSWORD", Value: RedactedSecretValue, Secret: true},
+		{Key: "API_TOKEN", Value: RedactedSecretValue, Secret: true},
+	}}
+
+	if err := settings.storeSecrets(incoming, true); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"global/DB_PASSWORD": "hunter2", "global/API_TOKEN": "abc"}
+	if !maps.Equal(store.values, want) {
+		t.Errorf("stored secrets = %v, want %v", store.values, want)
+	}
+}
+
+func TestStoreSecretsRejectsAmbiguousRename(t *testing.T) {
+	settings, _ := settingsWithSecrets(
+		[]EnvParam{{Key: "A", Secret: true}, {Key: "B", Secret: true}},
+		map[string]string{"global/A": "1", "global/B": "2"},
+	)
+	incoming := &Settings{EnvParams: []EnvParam{
+		{Key: "C", Value: RedactedSecretValue, Secret: true},
+		{Key: "D", Value: RedactedSecretValue, Secret: true},
+	}}
+
+	err := settings.storeSecrets(incoming, true)
+	if !errors.Is(err, ErrSecretRenamed) {
+		t.Errorf("storeSecrets() error = %v, want ErrSecretRenamed", err)
+	}
+}
+
+func TestStoreSecretsUncheckingSecretMakesItPlaintext(t *testing.T) {
+	settings, store := settingsWithSecrets(
+		[]EnvParam{{Key: "DB_PASSWORD", Secret: true}},
+		map[string]string{"global/DB_PASSWORD": "hunter2"},
+	)
+	incoming := &Settings{EnvParams: []EnvParam{{Key: "DB_PASSWORD", Value: RedactedSecretValue}}}
+
+	if err := settings.storeSecrets(incoming, true); err != nil {
+		t.Fatal(err)
+	}
+	param := incoming.EnvParams[0]
+	if param.Secret || param.Value != "hunter2" {
+		t.Errorf("param = %+v, want the stored value as plaintext", param)
+	}
+	if _, found := store.values["global/DB_PASSWORD"]; found {
+		t.Error("secret is still stored after it became plaintext")
+	}
+}
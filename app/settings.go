@@ -2,21 +2,27 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"phaas-localservices-ui/secrets"
+	"sync"
 )
 
 const ReposLocationChangedEvent = "repos-location-changed"
 
+// Settings is bound to the frontend, so every exported method can be called from it. Go code that must not be reachable
+// from the frontend, or that reads settings for other packages, is a package function taking the settings instead,
+// e.g. RepoSettingsOf or AddChangeListener.
 type Settings struct {
 	ctx context.Context
 
+	SchemaVersion int `json:"schemaVersion"`
+
 	ReposDirPath        string `json:"reposDirPath"`
 	DataDirPath         string `json:"dataDirPath"`
 	ShellExecutablePath string `json:"shellExecutablePath"`
@@ -24,7 +30,43 @@ type Settings struct {
 
 	EnvParams []EnvParam `json:"envParams"`
 
+	EnvPresets      []EnvPreset `json:"envPresets"`
+	ActiveEnvPreset string      `json:"activeEnvPreset"`
+
+	Repos map[string]RepoSettings `json:"repos"`
+
+	Notifications NotificationSettings `json:"notifications"`
+
+	ServiceLogs ServiceLogRetention `json:"serviceLogs"`
+	AppLogs     AppLogRetention     `json:"appLogs"`
+	// LogLevel is the minimum level written to the app log: debug, info, warn or error. Empty means info.
+	LogLevel string `json:"logLevel"`
+
+	Docker DockerSettings `json:"docker"`
+
 	settingsPath string
+	secrets      secrets.Store
+
+	// readOnly is set when settings.json comes from a newer build, so this one doesn't overwrite what it can't read
+	readOnly bool
+
+	loadError        error
+	validationErrors []FieldError
+	shellInitError   error
+
+	changeListeners []ChangeListener
+
+	// mutex serializes changes to the settings and to settings.json: saves, imports, preset switches and reloads by the
+	// file watcher. It is a pointer so that copies of the settings, e.g. from redacted, don't copy the lock.
+	mutex *sync.Mutex
+
+	// fileHash is the hash of settings.json as last read or written by the app, so the file watcher can tell
+	// external edits apart from the app's own writes
+	fileHash [sha256.Size]byte
+}
+
+func NewSettings() *Settings {
+	return &Settings{mutex: &sync.Mutex{}}
 }
 
 func GetSettingsDir() (string, error) {
@@ -36,15 +78,34 @@ func GetSettingsDir() (string, error) {
 	return filepath.Join(configDir, "phaas-localservices-manager"), nil
 }
 
+// Startup loads and validates the settings. A failure to load is returned but also kept as a problem in
+// GetSettingsStatus so the app can keep running in degraded mode until the settings are fixed.
 func (this *Settings) Startup(ctx context.Context) error {
 	this.ctx = ctx
 
+	err := this.load()
+	if err != nil {
+		previous := this.GetSettingsStatus()
+		this.loadError = err
+		this.emitStatusIfChanged(previous)
+		return err
+	}
+	this.validateAndRecord()
+	return nil
+}
+
+func (this *Settings) load() error {
 	settingsDir, err := GetSettingsDir()
 	if err != nil {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Could not get user settings dir")
 		return fmt.Errorf("could not get user settings dir: %w", err)
 	}
 	this.settingsPath = filepath.Join(settingsDir, "settings.json")
+	this.secrets, err = secrets.NewStore(this.ctx, settingsDir)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Could not open secret store")
+		return fmt.Errorf("could not open secret store: %w", err)
+	}
 	if _, err := os.Stat(this.settingsPath); errors.Is(err, os.ErrNotExist) {
 		err = os.MkdirAll(settingsDir, os.ModePerm)
 		if err != nil && !errors.Is(err, os.ErrExist) {
@@ -64,63 +125,255 @@ func (this *Settings) Startup(ctx context.Context) error {
 		return fmt.Errorf("failed to read settings: %w", err)
 	}
 
+	this.rememberFileContents(settingsJSON)
 	if len(settingsJSON) == 0 {
 		return nil
 	}
 
-	err = json.Unmarshal(settingsJSON, this)
+	migratedJSON, fromVersion, migrated, err := migrateSettings(settingsJSON)
+	if err != nil {
+		if errors.Is(err, ErrSettingsFromNewerVersion) {
+			this.readOnly = true
+		}
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to migrate settings.json")
+		return fmt.Errorf("failed to migrate settings.json: %w", err)
+	}
+	if migrated {
+		err = this.backupSettings(settingsJSON, fromVersion)
+		if err != nil {
+			slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to back up settings.json")
+			return err
+		}
+	}
+
+	err = json.Unmarshal(migratedJSON, this)
 	if err != nil {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to unmarshal settings.json")
 		return fmt.Errorf("failed to unmarshal settings.json: %w", err)
 	}
-	slog.With(slog.Any("settings", this)).InfoContext(ctx, "Loaded with settings")
+	if migrated {
+		err = this.writeToFile()
+		if err != nil {
+			slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to save migrated settings")
+			return err
+		}
+		slog.With(slog.Int("from", fromVersion), slog.Int("to", CurrentSchemaVersion)).InfoContext(this.ctx, "Migrated settings")
+	}
+	if this.hasPlaintextSecrets() {
+		// secret values added by hand-editing settings.json are moved into the secret store
+		err = this.storeSecrets(this, false)
+		if err == nil {
+			err = this.writeToFile()
+		}
+		if err != nil {
+			slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to move secrets out of settings.json")
+		}
+	}
+	slog.With(slog.Any("settings", this.redacted())).InfoContext(this.ctx, "Loaded with settings")
 
 	return nil
 }
 
 func (this *Settings) GetSettings() Settings {
-	return *this
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.redacted()
 }
 
-func (this *Settings) SaveSettings(settings Settings) error {
-	reposDirChanged := this.ReposDirPath != settings.ReposDirPath
-	this.ReposDirPath = settings.ReposDirPath
-	this.DataDirPath = settings.DataDirPath
-	this.EnvParams = settings.EnvParams
+// SettingsUpdate holds the settings a save from the frontend changes. Fields that are left out keep their current
+// value, so a page that only edits some of the settings can't wipe the rest. For EnvParams, EnvPresets and Repos nil
+// means unchanged and an empty value clears them.
+type SettingsUpdate struct {
+	ReposDirPath        *string                 `json:"reposDirPath,omitempty"`
+	DataDirPath         *string                 `json:"dataDirPath,omitempty"`
+	ShellExecutablePath *string                 `json:"shellExecutablePath,omitempty"`
+	ShellInitFilePath   *string                 `json:"shellInitFilePath,omitempty"`
+	EnvParams           []EnvParam              `json:"envParams,omitempty"`
+	EnvPresets          []EnvPreset             `json:"envPresets,omitempty"`
+	ActiveEnvPreset     *string                 `json:"activeEnvPreset,omitempty"`
+	Repos               map[string]RepoSettings `json:"repos,omitempty"`
+	Notifications       *NotificationSettings   `json:"notifications,omitempty"`
+	ServiceLogs         *ServiceLogRetention    `json:"serviceLogs,omitempty"`
+	AppLogs             *AppLogRetention        `json:"appLogs,omitempty"`
+	LogLevel            *string                 `json:"logLevel,omitempty"`
+	Docker              *DockerSettings         `json:"docker,omitempty"`
+}
 
-	err := this.writeToFile()
-	if err != nil {
-		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to save app settings")
-		return fmt.Errorf("failed to save app settings: %w", err)
+func setIfPresent[T any](target *T, value *T) {
+	if value != nil {
+		*target = *value
 	}
-	if reposDirChanged {
-		runtime.EventsEmit(this.ctx, ReposLocationChangedEvent)
+}
+
+func (this SettingsUpdate) applyTo(settings *Settings) {
+	setIfPresent(&settings.ReposDirPath, this.ReposDirPath)
+	setIfPresent(&settings.DataDirPath, this.DataDirPath)
+	setIfPresent(&settings.ShellExecutablePath, this.ShellExecutablePath)
+	setIfPresent(&settings.ShellInitFilePath, this.ShellInitFilePath)
+	if this.EnvParams != nil {
+		settings.EnvParams = this.EnvParams
+	}
+	if this.EnvPresets != nil {
+		settings.EnvPresets = this.EnvPresets
+	}
+	setIfPresent(&settings.ActiveEnvPreset, this.ActiveEnvPreset)
+	if this.Repos != nil {
+		settings.Repos = this.Repos
+	}
+	setIfPresent(&settings.Notifications, this.Notifications)
+	setIfPresent(&settings.ServiceLogs, this.ServiceLogs)
+	setIfPresent(&settings.AppLogs, this.AppLogs)
+	setIfPresent(&settings.LogLevel, this.LogLevel)
+	setIfPresent(&settings.Docker, this.Docker)
+}
+
+// SaveSettings merges the fields set in update onto the current settings and saves the result
+func (this *Settings) SaveSettings(update SettingsUpdate) error {
+	return this.update(func(updated *Settings) error {
+		update.applyTo(updated)
+		return nil
+	})
+}
+
+// update runs modify on a redacted copy of the current settings and saves the result, so unchanged secrets keep
+// their stored values. The mutex is held from reading the current settings until the file is written so concurrent
+// saves and reloads can't lose each other's changes. Listeners are notified after it is released since they read
+// the settings.
+func (this *Settings) update(modify func(updated *Settings) error) error {
+	this.mutex.Lock()
+	updated := this.redacted()
+	err := modify(&updated)
+	var change SettingsChange
+	if err == nil {
+		change, err = this.apply(&updated, true, true)
 	}
+	this.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	this.notifyChanged(change)
 	return nil
 }
 
+// apply validates incoming and makes it the current settings, notifying listeners of the fields that changed.
+// emptySecretsClear says whether an empty secret value means the user cleared it (see storeSecrets) and write
+// whether incoming should be written to settings.json. It must be called with the mutex held, and the caller notifies
+// listeners of the returned change once the mutex is released.
+func (this *Settings) apply(incoming *Settings, emptySecretsClear bool, write bool) (SettingsChange, error) {
+	// only problems in fields being changed block the save, so an unrelated broken field (say a repo's env file that
+	// was deleted) doesn't make every other setting unsaveable. Incoming secrets are redacted unless changed, so the
+	// comparison is against the redacted current settings.
+	redactedCurrent := this.redacted()
+	validationErr := asValidationError(problemsIn(incoming.Validate(), diffSettings(&redactedCurrent, incoming)))
+	if validationErr != nil {
+		slog.With(slog.Any("error", validationErr)).WarnContext(this.ctx, "Refusing to save invalid settings")
+		return SettingsChange{}, validationErr
+	}
+
+	hadPlaintextSecrets := incoming.hasPlaintextSecrets()
+	err := this.storeSecrets(incoming, emptySecretsClear)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to store secrets")
+		return SettingsChange{}, fmt.Errorf("failed to save app settings: %w", err)
+	}
+
+	change := diffSettings(this, incoming)
+	this.ReposDirPath = incoming.ReposDirPath
+	this.DataDirPath = incoming.DataDirPath
+	this.ShellExecutablePath = incoming.ShellExecutablePath
+	this.ShellInitFilePath = incoming.ShellInitFilePath
+	this.EnvParams = incoming.EnvParams
+	this.EnvPresets = incoming.EnvPresets
+	this.ActiveEnvPreset = incoming.ActiveEnvPreset
+	this.Repos = incoming.Repos
+	this.Notifications = incoming.Notifications
+	this.ServiceLogs = incoming.ServiceLogs
+	this.AppLogs = incoming.AppLogs
+	this.LogLevel = incoming.LogLevel
+	this.Docker = incoming.Docker
+
+	if write || hadPlaintextSecrets {
+		err = this.writeToFile()
+		if err != nil {
+			slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to save app settings")
+			return SettingsChange{}, fmt.Errorf("failed to save app settings: %w", err)
+		}
+	}
+	previous := this.GetSettingsStatus()
+	this.loadError = nil
+	this.emitStatusIfChanged(previous)
+	this.validateAndRecord()
+	return change, nil
+}
+
 type EnvParam struct {
 	Key     string `json:"key"`
 	Value   string `json:"value"`
 	Enabled bool   `json:"enabled"`
+	// Secret params keep their value in the system keyring rather than in settings.json
+	Secret bool `json:"secret"`
 }
 
-func (this *Settings) GetEnvParamOverrides() []EnvParam {
-	return this.EnvParams
-}
+var ErrSettingsReadOnly = errors.New("settings are read-only")
 
+// writeToFile writes the settings atomically: to a temp file in the same directory that is then renamed over
+// settings.json, so a crash mid-save leaves either the old or the new file. When settings.json is a symlink, e.g. into
+// a dotfiles repo, the file it points to is replaced and the link is kept.
 func (this *Settings) writeToFile() error {
+	if this.readOnly {
+		return fmt.Errorf("%w: %w", ErrSettingsReadOnly, ErrSettingsFromNewerVersion)
+	}
+	this.SchemaVersion = CurrentSchemaVersion
 	settingsJSON, err := json.MarshalIndent(this, "", "  ")
 	if err != nil {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to marshal settings to json to save to file")
 		return fmt.Errorf("failed to marshal settings to json to save to file: %s", err)
 	}
 
-	err = os.WriteFile(this.settingsPath, settingsJSON, os.ModePerm)
+	targetPath := this.settingsPath
+	resolvedPath, err := filepath.EvalSymlinks(targetPath)
+	if err == nil {
+		targetPath = resolvedPath
+	} else if !errors.Is(err, os.ErrNotExist) {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to resolve settings file path")
+		return fmt.Errorf("failed to resolve settings file path: %w", err)
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(targetPath), "settings-*.json.tmp")
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to create temp settings file")
+		return fmt.Errorf("failed to create temp settings file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath)
+
+	_, err = tempFile.Write(settingsJSON)
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	if err == nil {
+		// CreateTemp already uses 0600, this keeps it explicit since the file holds env values
+		err = tempFile.Chmod(0o600)
+	}
+	closeErr := tempFile.Close()
+	if err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to save settings to file")
 		return fmt.Errorf("failed to save settings to file: %s", err)
 	}
 
+	// remembered before the rename so the file watcher never sees the new file with the old hash and reloads the app's
+	// own write
+	previousHash := this.fileHash
+	this.rememberFileContents(settingsJSON)
+	err = os.Rename(tempPath, targetPath)
+	if err != nil {
+		this.fileHash = previousHash
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to replace settings file")
+		return fmt.Errorf("failed to replace settings file: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"reflect"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const SettingsChangedEvent = "settings-changed"
+
+// Field names used in SettingsChange match the settings' JSON field names
+const (
+	FieldReposDirPath        = "reposDirPath"
+	FieldDataDirPath         = "dataDirPath"
+	FieldShellExecutablePath = "shellExecutablePath"
+	FieldShellInitFilePath   = "shellInitFilePath"
+	FieldEnvParams           = "envParams"
+	FieldEnvPresets          = "envPresets"
+	FieldActiveEnvPreset     = "activeEnvPreset"
+	FieldRepos               = "repos"
+	FieldNotifications       = "notifications"
+	FieldServiceLogs         = "serviceLogs"
+	FieldAppLogs             = "appLogs"
+	FieldLogLevel            = "logLevel"
+	FieldDocker              = "docker"
+)
+
+type SettingsChange struct {
+	Fields []string `json:"fields"`
+}
+
+func (this SettingsChange) Has(fields ...string) bool {
+	for _, changed := range this.Fields {
+		for _, field := range fields {
+			if changed == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// emitEvent sends an event to the frontend. Tests replace it since they run without one.
+var emitEvent = runtime.EventsEmit
+
+type ChangeListener func(SettingsChange)
+
+// AddChangeListener registers a listener that is called after settings are saved with the fields that changed
+func AddChangeListener(settings *Settings, listener ChangeListener) {
+	settings.changeListeners = append(settings.changeListeners, listener)
+}
+
+func diffSettings(current *Settings, incoming *Settings) SettingsChange {
+	change := SettingsChange{Fields: []string{}}
+	add := func(field string, changed bool) {
+		if changed {
+			change.Fields = append(change.Fields, field)
+		}
+	}
+	add(FieldReposDirPath, current.ReposDirPath != incoming.ReposDirPath)
+	add(FieldDataDirPath, current.DataDirPath != incoming.DataDirPath)
+	add(FieldShellExecutablePath, current.ShellExecutablePath != incoming.ShellExecutablePath)
+	add(FieldShellInitFilePath, current.ShellInitFilePath != incoming.ShellInitFilePath)
+	add(FieldEnvParams, !reflect.DeepEqual(current.EnvParams, incoming.EnvParams))
+	add(FieldEnvPresets, !reflect.DeepEqual(current.EnvPresets, incoming.EnvPresets))
+	add(FieldActiveEnvPreset, current.ActiveEnvPreset != incoming.ActiveEnvPreset)
+	add(FieldRepos, !reflect.DeepEqual(current.Repos, incoming.Repos))
+	add(FieldNotifications, current.Notifications != incoming.Notifications)
+	add(FieldServiceLogs, current.ServiceLogs != incoming.ServiceLogs)
+	add(FieldAppLogs, current.AppLogs != incoming.AppLogs)
+	add(FieldLogLevel, current.LogLevel != incoming.LogLevel)
+	add(FieldDocker, current.Docker != incoming.Docker)
+	return change
+}
+
+// notifyChanged runs the Go listeners first so that anything they rebuild is ready by the time the frontend
+// reacts to the events
+func (this *Settings) notifyChanged(change SettingsChange) {
+	if len(change.Fields) == 0 {
+		return
+	}
+	for _, listener := range this.changeListeners {
+		listener(change)
+	}
+	emitEvent(this.ctx, SettingsChangedEvent, change)
+	if change.Has(FieldReposDirPath) {
+		emitEvent(this.ctx, ReposLocationChangedEvent)
+	}
+	if change.Has(FieldActiveEnvPreset) {
+		emitEvent(this.ctx, EnvPresetChangedEvent, this.ActiveEnvPreset)
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSettingsUpdateKeepsFieldsLeftOut(t *testing.T) {
+	settings := Settings{
+		ReposDirPath:        "/repos",
+		ShellExecutablePath: "/bin/zsh",
+		EnvParams:           []EnvParam{{Key: "A", Value: "1", Enabled: true}},
+		Repos:               map[string]RepoSettings{"phaas-foo-api": {MuteNotifications: true}},
+		Docker:              DockerSettings{Context: "colima"},
+	}
+	reposDirPath := "/elsewhere"
+	SettingsUpdate{ReposDirPath: &reposDirPath}.applyTo(&settings)
+
+	if settings.ReposDirPath != "/elsewhere" {
+		t.Errorf("ReposDirPath = %q, want the updated value", settings.ReposDirPath)
+	}
+	if settings.ShellExecutablePath != "/bin/zsh" || settings.Docker.Context != "colima" {
+		t.Errorf("fields left out of the update were changed: %+v", settings)
+	}
+	if len(settings.EnvParams) != 1 || !settings.Repos["phaas-foo-api"].MuteNotifications {
+		t.Errorf("collections left out of the update were changed: %+v", settings)
+	}
+
+	SettingsUpdate{EnvParams: []EnvParam{}}.applyTo(&settings)
+	if !reflect.DeepEqual(settings.EnvParams, []EnvParam{}) {
+		t.Errorf("an empty EnvParams should clear them, got %v", settings.EnvParams)
+	}
+}
+
+func TestWriteToFileKeepsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	realPath := filepath.Join(dir, "dotfiles", "settings.json")
+	err := os.MkdirAll(filepath.Dir(realPath), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(realPath, []byte("{}"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linkPath := filepath.Join(dir, "settings.json")
+	err = os.Symlink(realPath, linkPath)
+	if err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	settings := &Settings{ctx: context.Background(), settingsPath: linkPath, ReposDirPath: "/repos"}
+	err = settings.writeToFile()
+	if err != nil {
+		t.Fatalf("writeToFile() error = %v", err)
+	}
+
+	info, err := os.Lstat(linkPath)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("settings.json is no longer a symlink: %v", err)
+	}
+	written, err := os.ReadFile(realPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(written), `"/repos"`) {
+		t.Errorf("link target was not updated: %s", written)
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"phaas-localservices-ui/scheduler"
+	"time"
+)
+
+const settingsWatcherJobName = "settings-file-watcher"
+
+const settingsWatchPeriod = 2 * time.Second
+
+func (this *Settings) rememberFileContents(settingsJSON []byte) {
+	this.fileHash = sha256.Sum256(settingsJSON)
+}
+
+// WatchSettingsFile polls settings.json and reloads it when it is changed by something other than the app, e.g. a
+// hand edit or a dotfiles sync
+func WatchSettingsFile(settings *Settings, jobScheduler *scheduler.Scheduler) error {
+	err := jobScheduler.AddJob(settingsWatcherJobName, settingsWatchPeriod, func() {
+		err := settings.reloadIfChangedExternally()
+		if err != nil {
+			slog.With(slog.Any("error", err)).ErrorContext(settings.ctx, "Failed to reload externally changed settings")
+		}
+	})
+	if err != nil && !errors.Is(err, scheduler.ErrJobAlreadyExists) {
+		return fmt.Errorf("failed to add settings file watcher job: %w", err)
+	}
+	return nil
+}
+
+func (this *Settings) reloadIfChangedExternally() error {
+	this.mutex.Lock()
+	change, err := this.reloadIfChangedExternallyLocked()
+	this.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	this.notifyChanged(change)
+	return nil
+}
+
+func (this *Settings) reloadIfChangedExternallyLocked() (SettingsChange, error) {
+	if this.settingsPath == "" {
+		return SettingsChange{}, nil
+	}
+	settingsJSON, err := os.ReadFile(this.settingsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return SettingsChange{}, nil
+	}
+	if err != nil {
+		return SettingsChange{}, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if sha256.Sum256(settingsJSON) == this.fileHash {
+		return SettingsChange{}, nil
+	}
+	// remembered up front so a broken edit is reported once rather than on every poll
+	this.rememberFileContents(settingsJSON)
+	slog.InfoContext(this.ctx, "settings.json changed on disk, reloading")
+
+	fileUpdate, err := parseExternalChange(settingsJSON)
+	if err == nil && this.readOnly {
+		// the file from a newer build was replaced with one this build understands, so it may write again
+		slog.InfoContext(this.ctx, "settings.json is readable again, leaving read-only mode")
+		this.readOnly = false
+	}
+	if err != nil {
+		previous := this.GetSettingsStatus()
+		this.loadError = fmt.Errorf("external changes to settings.json were not applied: %w", err)
+		this.emitStatusIfChanged(previous)
+		return SettingsChange{}, err
+	}
+
+	// the edit is merged onto the current settings, so fields missing from the file keep their values instead of
+	// being cleared
+	incoming := this.redacted()
+	fileUpdate.applyTo(&incoming)
+	change, err := this.apply(&incoming, false, false)
+	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			previous := this.GetSettingsStatus()
+			this.loadError = fmt.Errorf("external changes to settings.json were not applied: %w", err)
+			this.emitStatusIfChanged(previous)
+		}
+		return SettingsChange{}, err
+	}
+	return change, nil
+}
+
+// parseExternalChange reads the fields present in settingsJSON. SettingsUpdate leaves the fields missing from the
+// file unset.
+func parseExternalChange(settingsJSON []byte) (SettingsUpdate, error) {
+	var update SettingsUpdate
+	if len(settingsJSON) == 0 {
+		return update, nil
+	}
+	migratedJSON, _, _, err := migrateSettings(settingsJSON)
+	if err != nil {
+		return SettingsUpdate{}, err
+	}
+	err = json.Unmarshal(migratedJSON, &update)
+	if err != nil {
+		return SettingsUpdate{}, fmt.Errorf("failed to unmarshal settings.json: %w", err)
+	}
+	return update, nil
+}
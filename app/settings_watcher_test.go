@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// recordEvents replaces emitEvent for the test and returns the names of the events emitted so far
+func recordEvents(t *testing.T) func() []string {
+	t.Helper()
+	var names []string
+	original := emitEvent
+	emitEvent = func(ctx context.Context, eventName string, optionalData ...any) {
+		names = append(names, eventName)
+	}
+	t.Cleanup(func() {
+		emitEvent = original
+	})
+	return func() []string {
+		emitted := names
+		names = nil
+		return emitted
+	}
+}
+
+// watchedSettings returns valid settings that have been written to a settings.json in a temp dir, along with the
+// changes passed to the change listeners
+func watchedSettings(t *testing.T) (*Settings, *[]SettingsChange) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"repos", "data"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	shellPath := filepath.Join(dir, "zsh")
+	if err := os.WriteFile(shellPath, nil, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := NewSettings()
+	settings.ctx = context.Background()
+	settings.secrets = &memoryStore{values: map[string]string{}}
+	settings.settingsPath = filepath.Join(dir, "settings.json")
+	settings.ReposDirPath = filepath.Join(dir, "repos")
+	settings.DataDirPath = filepath.Join(dir, "data")
+	settings.ShellExecutablePath = shellPath
+	settings.LogLevel = "info"
+	if err := settings.writeToFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := &[]SettingsChange{}
+	AddChangeListener(settings, func(change SettingsChange) {
+		*changes = append(*changes, change)
+	})
+	return settings, changes
+}
+
+func writeSettingsFile(t *testing.T, settings *Settings, content string) {
+	t.Helper()
+	if err := os.WriteFile(settings.settingsPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSettingsWatcherIgnoresOwnWrites(t *testing.T) {
+	events := recordEvents(t)
+	settings, changes := watchedSettings(t)
+	logLevel := "debug"
+	if err := settings.SaveSettings(SettingsUpdate{LogLevel: &logLevel}); err != nil {
+		t.Fatal(err)
+	}
+	events()
+	*changes = nil
+
+	if err := settings.reloadIfChangedExternally(); err != nil {
+		t.Fatal(err)
+	}
+	if emitted := events(); len(emitted) > 0 || len(*changes) > 0 {
+		t.Errorf("reloading the app's own write emitted %v and notified %v", emitted, *changes)
+	}
+}
+
+func TestSettingsWatcherMergesExternalEdit(t *testing.T) {
+	events := recordEvents(t)
+	settings, changes := watchedSettings(t)
+	reposDirPath := settings.ReposDirPath
+	writeSettingsFile(t, settings, `{"schemaVersion": 1, "logLevel": "warn"}`)
+
+	if err := settings.reloadIfChangedExternally(); err != nil {
+		t.Fatal(err)
+	}
+	if settings.LogLevel != "warn" {
+		t.Errorf("LogLevel = %q, want the edited value", settings.LogLevel)
+	}
+	if settings.ReposDirPath != reposDirPath {
+		t.Errorf("ReposDirPath = %q, want %q kept since the edit left it out", settings.ReposDirPath, reposDirPath)
+	}
+	if len(*changes) != 1 || !(*changes)[0].Has(FieldLogLevel) || (*changes)[0].Has(FieldReposDirPath) {
+		t.Errorf("listeners were notified of %v, want only the log level", *changes)
+	}
+	if emitted := events(); !slices.Contains(emitted, SettingsChangedEvent) {
+		t.Errorf("events = %v, want %s", emitted, SettingsChangedEvent)
+	}
+}
+
+func TestSettingsWatcherDegradesOnInvalidEdit(t *testing.T) {
+	tests := []struct {
+		desc    string
+		content string
+	}{
+		{"malformed json", `{"reposDirPath": `},
+		{"invalid setting", `{"schemaVersion": 1, "reposDirPath": "/does/not/exist"}`},
+	}
+	for _, test := range tests {
+		events := recordEvents(t)
+		settings, changes := watchedSettings(t)
+		reposDirPath := settings.ReposDirPath
+		writeSettingsFile(t, settings, test.content)
+
+		if err := settings.reloadIfChangedExternally(); err == nil {
+			t.Errorf("%s: reloadIfChangedExternally() succeeded, want an error", test.desc)
+		}
+		if !settings.GetSettingsStatus().Degraded {
+			t.Errorf("%s: settings are not degraded", test.desc)
+		}
+		if settings.ReposDirPath != reposDirPath || len(*changes) > 0 {
+			t.Errorf("%s: the edit was applied: ReposDirPath = %q, changes %v", test.desc, settings.ReposDirPath, *changes)
+		}
+		if emitted := events(); !slices.Contains(emitted, SettingsStatusChangedEvent) {
+			t.Errorf("%s: events = %v, want %s", test.desc, emitted, SettingsStatusChangedEvent)
+		}
+
+		// the broken file is only reported once rather than on every poll
+		if err := settings.reloadIfChangedExternally(); err != nil {
+			t.Errorf("%s: second poll error = %v, want the unchanged file skipped", test.desc, err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+const SettingsStatusChangedEvent = "settings-status-changed"
+
+// FieldError describes a problem with a single settings field. Field is a JSON path into the settings, e.g.
+// "envParams[2].key" or "repos.phaas-foo-api.envFilePath", and is empty for problems not tied to one field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (this *ValidationError) Error() string {
+	messages := make([]string, 0, len(this.Errors))
+	for _, fieldErr := range this.Errors {
+		if fieldErr.Field == "" {
+			messages = append(messages, fieldErr.Message)
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message))
+	}
+	return "invalid settings: " + strings.Join(messages, "; ")
+}
+
+// SettingsStatus reports whether the app is running with usable settings. While Degraded the repo list is not
+// loaded and the user should be sent to the settings page to fix the listed problems. Problems also lists issues that
+// don't stop the app from working, such as a single repo's missing env file, which never make it Degraded.
+type SettingsStatus struct {
+	Degraded bool         `json:"degraded"`
+	Problems []FieldError `json:"problems"`
+}
+
+type validator struct {
+	errors []FieldError
+}
+
+func (this *validator) add(field string, format string, args ...any) {
+	this.errors = append(this.errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (this *validator) requireDir(field string, path string) {
+	if path == "" {
+		this.add(field, "is required")
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		this.add(field, "%s does not exist", path)
+		return
+	}
+	if !info.IsDir() {
+		this.add(field, "%s is not a directory", path)
+	}
+}
+
+func (this *validator) requireFile(field string, path string) os.FileInfo {
+	info, err := os.Stat(path)
+	if err != nil {
+		this.add(field, "%s does not exist", path)
+		return nil
+	}
+	if info.IsDir() {
+		this.add(field, "%s is a directory", path)
+		return nil
+	}
+	return info
+}
+
+func (this *validator) envParams(field string, params []EnvParam) {
+	seen := map[string]int{}
+	for i, param := range params {
+		keyField := fmt.Sprintf("%s[%d].key", field, i)
+		if param.Key == "" {
+			this.add(keyField, "is required")
+			continue
+		}
+		if !envKeyRegex.MatchString(param.Key) {
+			this.add(keyField, "'%s' may only contain letters, digits and underscores and must not start with a digit", param.Key)
+		}
+		key := strings.ToUpper(param.Key)
+		if first, found := seen[key]; found {
+			this.add(keyField, "duplicates %s[%d].key '%s'", field, first, params[first].Key)
+			continue
+		}
+		seen[key] = i
+	}
+}
+
+// Validate checks the settings and returns every problem found, or an empty list if they are usable
+func (this *Settings) Validate() []FieldError {
+	v := &validator{errors: []FieldError{}}
+
+	v.requireDir("reposDirPath", this.ReposDirPath)
+	v.requireDir("dataDirPath", this.DataDirPath)
+	if this.ShellExecutablePath == "" {
+		v.add("shellExecutablePath", "is required")
+	} else if info := v.requireFile("shellExecutablePath", this.ShellExecutablePath); info != nil && info.Mode().Perm()&0o111 == 0 {
+		v.add("shellExecutablePath", "%s is not executable", this.ShellExecutablePath)
+	}
+	if this.ShellInitFilePath != "" {
+		v.requireFile("shellInitFilePath", this.ShellInitFilePath)
+	}
+
+	v.envParams("envParams", this.EnvParams)
+
+	presetNames := map[string]bool{}
+	for i, preset := range this.EnvPresets {
+		field := fmt.Sprintf("envPresets[%d]", i)
+		if preset.Name == "" {
+			v.add(field+".name", "is required")
+		} else if presetNames[preset.Name] {
+			v.add(field+".name", "another preset is already named '%s'", preset.Name)
+		}
+		presetNames[preset.Name] = true
+		v.envParams(field+".envParams", preset.EnvParams)
+	}
+	if this.ActiveEnvPreset != "" && !presetNames[this.ActiveEnvPreset] {
+		v.add("activeEnvPreset", "no preset is named '%s'", this.ActiveEnvPreset)
+	}
+
+	if this.ServiceLogs.MaxRuns < 0 {
+		v.add(FieldServiceLogs+".maxRuns", "must not be negative")
+	}
+	if this.ServiceLogs.MaxTotalSizeMB < 0 {
+		v.add(FieldServiceLogs+".maxTotalSizeMB", "must not be negative")
+	}
+
+	if this.AppLogs.MaxSizeMB < 0 || this.AppLogs.MaxAgeDays < 0 || this.AppLogs.MaxBackups < 0 {
+		v.add(FieldAppLogs, "limits must not be negative")
+	}
+	if this.LogLevel != "" {
+		var level slog.Level
+		if level.UnmarshalText([]byte(this.LogLevel)) != nil {
+			v.add(FieldLogLevel, "unknown log level '%s', expected debug, info, warn or error", this.LogLevel)
+		}
+	}
+
+	this.Docker.validate(v)
+
+	repoNames := make([]string, 0, len(this.Repos))
+	for repoName := range this.Repos {
+		repoNames = append(repoNames, repoName)
+	}
+	slices.Sort(repoNames)
+	for _, repoName := range repoNames {
+		repoSettings := this.Repos[repoName]
+		field := "repos." + repoName
+		switch repoSettings.RestartPolicy.Mode {
+		case "", RestartNever, RestartOnFailure, RestartAlways:
+		default:
+			v.add(field+".restartPolicy.mode", "unknown restart mode '%s'", repoSettings.RestartPolicy.Mode)
+		}
+		if repoSettings.RestartPolicy.MaxRetries < 0 {
+			v.add(field+".restartPolicy.maxRetries", "must not be negative")
+		}
+		v.envParams(field+".envParams", repoSettings.EnvParams)
+		if repoSettings.EnvFilePath != "" {
+			v.requireFile(field+".envFilePath", repoSettings.EnvFilePath)
+		}
+	}
+
+	return v.errors
+}
+
+// validateAndRecord runs Validate and keeps the result for GetSettingsStatus, notifying the frontend when the
+// status changes
+func (this *Settings) validateAndRecord() []FieldError {
+	previous := this.GetSettingsStatus()
+	this.validationErrors = this.Validate()
+	if len(this.validationErrors) > 0 {
+		slog.With(slog.Any("problems", this.validationErrors)).WarnContext(this.ctx, "Settings are invalid")
+	}
+	this.emitStatusIfChanged(previous)
+	return this.validationErrors
+}
+
+func (this *Settings) emitStatusIfChanged(previous SettingsStatus) {
+	current := this.GetSettingsStatus()
+	if current.Degraded != previous.Degraded || !slices.Equal(current.Problems, previous.Problems) {
+		emitEvent(this.ctx, SettingsStatusChangedEvent, current)
+	}
+}
+
+func (this *Settings) GetSettingsStatus() SettingsStatus {
+	problems := make([]FieldError, 0)
+	if this.loadError != nil {
+		problems = append(problems, FieldError{Message: this.loadError.Error()})
+	}
+	problems = append(problems, this.validationErrors...)
+	if this.shellInitError != nil {
+		problems = append(problems, FieldError{Field: "shellInitFilePath", Message: this.shellInitError.Error()})
+	}
+	degraded := false
+	for _, problem := range problems {
+		if isBlockingProblem(problem) {
+			degraded = true
+			break
+		}
+	}
+	return SettingsStatus{
+		Degraded: degraded,
+		Problems: problems,
+	}
+}
+
+// blockingFields are the settings the app can't work without. Problems not tied to a field, like failing to load
+// settings.json, block as well.
+var blockingFields = []string{"", FieldReposDirPath, FieldDataDirPath, FieldShellExecutablePath, FieldShellInitFilePath}
+
+func isBlockingProblem(problem FieldError) bool {
+	return slices.Contains(blockingFields, topLevelField(problem.Field))
+}
+
+// topLevelField returns the settings field a FieldError path points into, e.g. "repos" for
+// "repos.phaas-foo-api.envFilePath"
+func topLevelField(field string) string {
+	end := strings.IndexAny(field, ".[")
+	if end < 0 {
+		return field
+	}
+	return field[:end]
+}
+
+// dependsOn lists fields whose validity depends on another field, so changing the other field re-checks them
+var dependsOn = map[string]string{
+	FieldActiveEnvPreset: FieldEnvPresets,
+}
+
+// problemsIn keeps the problems that belong to one of the changed fields
+func problemsIn(problems []FieldError, change SettingsChange) []FieldError {
+	kept := make([]FieldError, 0, len(problems))
+	for _, problem := range problems {
+		field := topLevelField(problem.Field)
+		if change.Has(field) || (dependsOn[field] != "" && change.Has(dependsOn[field])) {
+			kept = append(kept, problem)
+		}
+	}
+	return kept
+}
+
+// ReportShellInitFailure records that the shell could not be initialised with the current settings, putting the
+// app in degraded mode. Passing a nil error clears the failure.
+func ReportShellInitFailure(settings *Settings, err error) {
+	previous := settings.GetSettingsStatus()
+	settings.shellInitError = err
+	settings.emitStatusIfChanged(previous)
+}
+
+func asValidationError(fieldErrors []FieldError) error {
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: fieldErrors}
+}
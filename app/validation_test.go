@@ -0,0 +1,45 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProblemsIn(t *testing.T) {
+	problems := []FieldError{
+		{Field: "reposDirPath", Message: "is required"},
+		{Field: "repos.phaas-foo-api.envFilePath", Message: "does not exist"},
+		{Field: "envParams[1].key", Message: "is required"},
+		{Field: "activeEnvPreset", Message: "no preset is named 'dev'"},
+	}
+
+	got := problemsIn(problems, SettingsChange{Fields: []string{FieldEnvParams}})
+	want := []FieldError{problems[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problemsIn(envParams) = %v, want %v", got, want)
+	}
+
+	got = problemsIn(problems, SettingsChange{Fields: []string{FieldEnvPresets}})
+	want = []FieldError{problems[3]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problemsIn(envPresets) = %v, want %v", got, want)
+	}
+
+	got = problemsIn(problems, SettingsChange{Fields: []string{FieldNotifications}})
+	if len(got) != 0 {
+		t.Errorf("problemsIn(notifications) = %v, want none", got)
+	}
+}
+
+func TestSettingsStatusOnlyDegradesForBlockingProblems(t *testing.T) {
+	settings := &Settings{validationErrors: []FieldError{{Field: "repos.phaas-foo-api.envFilePath", Message: "does not exist"}}}
+	status := settings.GetSettingsStatus()
+	if status.Degraded || len(status.Problems) != 1 {
+		t.Errorf("expected a listed but non-degrading problem, got %+v", status)
+	}
+
+	settings.validationErrors = append(settings.validationErrors, FieldError{Field: "shellExecutablePath", Message: "is required"})
+	if !settings.GetSettingsStatus().Degraded {
+		t.Error("expected a missing shell to degrade the app")
+	}
+}
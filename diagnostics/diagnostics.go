@@ -0,0 +1,171 @@
+package diagnostics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultAppLogLimit = 500
+
+// LogLevel is the level of the app's slog handler, adjustable at runtime from the settings
+var LogLevel = new(slog.LevelVar)
+
+// ParseLevel parses a level name from the settings, where an empty name means info
+func ParseLevel(name string) (slog.Level, error) {
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	err := level.UnmarshalText([]byte(name))
+	if err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level '%s'", name)
+	}
+	return level, nil
+}
+
+type AppLogFilter struct {
+	// MinLevel drops entries below the level, e.g. "warn". Empty keeps every level.
+	MinLevel string `json:"minLevel"`
+	// Search keeps entries whose message or attributes contain the text, case-insensitively
+	Search string    `json:"search"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+	// Limit caps the number of entries returned, newest first. Zero uses a default of 500.
+	Limit int `json:"limit"`
+	// IncludeRotated also searches rotated log files from earlier sessions
+	IncludeRotated bool `json:"includeRotated"`
+}
+
+type AppLogEntry struct {
+	Time  time.Time      `json:"time"`
+	Level string         `json:"level"`
+	Msg   string         `json:"msg"`
+	Attrs map[string]any `json:"attrs"`
+}
+
+// Diagnostics exposes the app's own logs to the frontend
+type Diagnostics struct {
+	ctx       context.Context
+	logWriter *RotatingWriter
+}
+
+func NewDiagnostics(logWriter *RotatingWriter) *Diagnostics {
+	return &Diagnostics{
+		ctx:       context.Background(),
+		logWriter: logWriter,
+	}
+}
+
+func (this *Diagnostics) Startup(ctx context.Context) {
+	this.ctx = ctx
+}
+
+// GetAppLogs returns the app log entries matching the filter, newest first
+func (this *Diagnostics) GetAppLogs(filter AppLogFilter) ([]AppLogEntry, error) {
+	minLevel := slog.Level(-1 << 10)
+	if filter.MinLevel != "" {
+		level, err := ParseLevel(filter.MinLevel)
+		if err != nil {
+			return nil, err
+		}
+		minLevel = level
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAppLogLimit
+	}
+	search := strings.ToLower(filter.Search)
+
+	paths := this.logWriter.LogFiles()
+	if !filter.IncludeRotated {
+		paths = paths[:1]
+	}
+	entries := make([]AppLogEntry, 0)
+	for _, path := range paths {
+		fileEntries, err := readAppLogFile(path)
+		if err != nil {
+			slog.With(slog.Any("error", err), slog.String("path", path)).WarnContext(this.ctx, "Failed to read app log file")
+			continue
+		}
+		// files are newest first but their lines are oldest first
+		for i := len(fileEntries) - 1; i >= 0; i-- {
+			entry := fileEntries[i]
+			if !matchesFilter(entry.entry, entry.line, filter, minLevel, search) {
+				continue
+			}
+			entries = append(entries, entry.entry)
+			if len(entries) >= limit {
+				return entries, nil
+			}
+		}
+	}
+	return entries, nil
+}
+
+type parsedLine struct {
+	entry AppLogEntry
+	line  string
+}
+
+func readAppLogFile(path string) ([]parsedLine, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([]parsedLine, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		entry, ok := parseAppLogLine(scanner.Bytes())
+		if !ok {
+			continue
+		}
+		lines = append(lines, parsedLine{entry: entry, line: strings.ToLower(scanner.Text())})
+	}
+	return lines, scanner.Err()
+}
+
+func parseAppLogLine(line []byte) (AppLogEntry, bool) {
+	var attrs map[string]any
+	if json.Unmarshal(line, &attrs) != nil {
+		return AppLogEntry{}, false
+	}
+	entry := AppLogEntry{Attrs: attrs}
+	if raw, ok := attrs[slog.TimeKey].(string); ok {
+		entry.Time, _ = time.Parse(time.RFC3339Nano, raw)
+	}
+	entry.Level, _ = attrs[slog.LevelKey].(string)
+	entry.Msg, _ = attrs[slog.MessageKey].(string)
+	delete(attrs, slog.TimeKey)
+	delete(attrs, slog.LevelKey)
+	delete(attrs, slog.MessageKey)
+	return entry, true
+}
+
+func matchesFilter(entry AppLogEntry, lowerLine string, filter AppLogFilter, minLevel slog.Level, search string) bool {
+	if entry.Level != "" {
+		level, err := ParseLevel(entry.Level)
+		if err == nil && level < minLevel {
+			return false
+		}
+	}
+	if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && entry.Time.After(filter.Until) {
+		return false
+	}
+	return search == "" || strings.Contains(lowerLine, search)
+}
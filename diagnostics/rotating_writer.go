@@ -0,0 +1,246 @@
+package diagnostics
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	appLogName        = "logs.json"
+	rotatedLogPrefix  = "logs-"
+	rotatedLogSuffix  = ".json"
+	rotatedLogTimeFmt = "20060102T150405.000"
+	defaultMaxSizeMB  = 10
+	defaultMaxAgeDays = 7
+	defaultMaxBackups = 5
+	bytesPerMB        = 1 << 20
+	hoursPerDay       = 24
+)
+
+type RotationLimits struct {
+	MaxSizeMB  int
+	MaxAgeDays int
+	MaxBackups int
+}
+
+// RotatingWriter appends to logs.json in a directory, moving it aside to a timestamped file when it grows past the
+// size limit or gets older than the age limit, and deleting rotated files beyond the backup and age limits
+type RotatingWriter struct {
+	mutex    sync.Mutex
+	dir      string
+	limits   RotationLimits
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func NewRotatingWriter(dir string) (*RotatingWriter, error) {
+	writer := &RotatingWriter{
+		dir: dir,
+		limits: RotationLimits{
+			MaxSizeMB:  defaultMaxSizeMB,
+			MaxAgeDays: defaultMaxAgeDays,
+			MaxBackups: defaultMaxBackups,
+		},
+	}
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	err := writer.open()
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (this *RotatingWriter) currentPath() string {
+	return filepath.Join(this.dir, appLogName)
+}
+
+// open opens logs.json for appending, rotating it first if it is already over the limits
+func (this *RotatingWriter) open() error {
+	info, err := os.Stat(this.currentPath())
+	if err == nil && this.exceedsLimits(info.Size(), firstEntryTime(this.currentPath(), info.ModTime()), 0) {
+		err = this.rotate()
+		if err != nil {
+			return err
+		}
+		info, err = nil, os.ErrNotExist
+	}
+	file, openErr := os.OpenFile(this.currentPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if openErr != nil {
+		return fmt.Errorf("could not open log file: %w", openErr)
+	}
+	this.file = file
+	this.size = 0
+	this.openedAt = time.Now()
+	if err == nil {
+		this.size = info.Size()
+		this.openedAt = firstEntryTime(this.currentPath(), info.ModTime())
+	}
+	return nil
+}
+
+// reopen opens the current log file again without rotating it, after a failed rotation left no file open. It must be
+// called with the mutex held.
+func (this *RotatingWriter) reopen() error {
+	file, err := os.OpenFile(this.currentPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("could not open log file: %w", err)
+	}
+	this.file = file
+	info, err := file.Stat()
+	if err == nil {
+		this.size = info.Size()
+	}
+	return nil
+}
+
+// firstEntryTime returns the time of the first entry in a log file, which is when it was started, falling back
+// to fallback if it can't be read
+func firstEntryTime(path string, fallback time.Time) time.Time {
+	file, err := os.Open(path)
+	if err != nil {
+		return fallback
+	}
+	defer file.Close()
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return fallback
+	}
+	var entry struct {
+		Time time.Time `json:"time"`
+	}
+	if json.Unmarshal(line, &entry) != nil || entry.Time.IsZero() {
+		return fallback
+	}
+	return entry.Time
+}
+
+// exceedsLimits must be called with the mutex held
+func (this *RotatingWriter) exceedsLimits(size int64, since time.Time, incoming int) bool {
+	if size == 0 {
+		return false
+	}
+	maxSize := int64(this.limits.MaxSizeMB) * bytesPerMB
+	maxAge := time.Duration(this.limits.MaxAgeDays) * hoursPerDay * time.Hour
+	return size+int64(incoming) > maxSize || time.Since(since) > maxAge
+}
+
+func (this *RotatingWriter) Write(p []byte) (int, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.file != nil && this.exceedsLimits(this.size, this.openedAt, len(p)) {
+		err := this.file.Close()
+		if err == nil {
+			err = this.rotate()
+		}
+		if err == nil {
+			err = this.open()
+		}
+		if err != nil {
+			// this writer is where the app logs go, so the failure can only be reported on stderr. Logging carries on
+			// in the current file and rotation is retried on the next write.
+			fmt.Fprintf(os.Stderr, "failed to rotate app log: %v\n", err)
+			this.file = nil
+		}
+	}
+	if this.file == nil {
+		err := this.reopen()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := this.file.Write(p)
+	this.size += int64(n)
+	return n, err
+}
+
+// SetLimits replaces the rotation limits. Zero values keep the defaults.
+func (this *RotatingWriter) SetLimits(limits RotationLimits) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if limits.MaxSizeMB <= 0 {
+		limits.MaxSizeMB = defaultMaxSizeMB
+	}
+	if limits.MaxAgeDays <= 0 {
+		limits.MaxAgeDays = defaultMaxAgeDays
+	}
+	if limits.MaxBackups <= 0 {
+		limits.MaxBackups = defaultMaxBackups
+	}
+	this.limits = limits
+	this.prune()
+}
+
+// rotate must be called with the mutex held and the current file closed
+func (this *RotatingWriter) rotate() error {
+	rotatedName := rotatedLogPrefix + time.Now().Format(rotatedLogTimeFmt) + rotatedLogSuffix
+	err := os.Rename(this.currentPath(), filepath.Join(this.dir, rotatedName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not rotate log file: %w", err)
+	}
+	this.prune()
+	return nil
+}
+
+// prune deletes rotated files beyond the backup count or age limit. It must be called with the mutex held.
+func (this *RotatingWriter) prune() {
+	rotated := this.rotatedFiles()
+	maxAge := time.Duration(this.limits.MaxAgeDays) * hoursPerDay * time.Hour
+	for i, file := range rotated {
+		if i < this.limits.MaxBackups && time.Since(file.rotatedAt) <= maxAge {
+			continue
+		}
+		// best effort, a failure here must not break logging
+		_ = os.Remove(file.path)
+	}
+}
+
+type rotatedFile struct {
+	path      string
+	rotatedAt time.Time
+}
+
+// rotatedFiles lists the rotated log files, newest first
+func (this *RotatingWriter) rotatedFiles() []rotatedFile {
+	entries, err := os.ReadDir(this.dir)
+	if err != nil {
+		return nil
+	}
+	files := make([]rotatedFile, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, rotatedLogPrefix) || !strings.HasSuffix(name, rotatedLogSuffix) {
+			continue
+		}
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(name, rotatedLogPrefix), rotatedLogSuffix)
+		rotatedAt, err := time.ParseInLocation(rotatedLogTimeFmt, timestamp, time.Local)
+		if err != nil {
+			continue
+		}
+		files = append(files, rotatedFile{path: filepath.Join(this.dir, name), rotatedAt: rotatedAt})
+	}
+	slices.SortFunc(files, func(a, b rotatedFile) int {
+		return b.rotatedAt.Compare(a.rotatedAt)
+	})
+	return files
+}
+
+// LogFiles returns the current log file followed by the rotated ones, newest first
+func (this *RotatingWriter) LogFiles() []string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	paths := []string{this.currentPath()}
+	for _, file := range this.rotatedFiles() {
+		paths = append(paths, file.path)
+	}
+	return paths
+}
@@ -0,0 +1,59 @@
+package dockerclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/moby/go-archive"
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+)
+
+// readDockerignore reads the exclude patterns of the context's .dockerignore, with the docker CLI's syntax and
+// semantics: ** globs, ! re-includes, including under excluded dirs, and the last matching pattern wins
+func readDockerignore(dir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .dockerignore: %w", err)
+	}
+	defer file.Close()
+	patterns, err := ignorefile.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse .dockerignore: %w", err)
+	}
+	return patterns, nil
+}
+
+// keepBuildFiles re-includes the Dockerfile and .dockerignore when the patterns exclude them, since the daemon needs
+// to read them from the context. The docker CLI does the same.
+func keepBuildFiles(patterns []string, dockerfile string) []string {
+	for _, name := range []string{filepath.ToSlash(filepath.Clean(dockerfile)), ".dockerignore"} {
+		if excluded, _ := patternmatcher.MatchesOrParentMatches(name, patterns); excluded {
+			patterns = append(patterns, "!"+name)
+		}
+	}
+	return patterns
+}
+
+// tarBuildContext streams the dir as a tar build context, leaving out what .dockerignore excludes
+func tarBuildContext(dir string, dockerfile string) (io.ReadCloser, error) {
+	patterns, err := readDockerignore(dir)
+	if err != nil {
+		return nil, err
+	}
+	buildContext, err := archive.TarWithOptions(dir, &archive.TarOptions{
+		ExcludePatterns: keepBuildFiles(patterns, dockerfile),
+		// like the docker CLI, so the files' owners on the host don't leak into the image
+		ChownOpts: &archive.ChownOpts{UID: 0, GID: 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive build context: %w", err)
+	}
+	return buildContext, nil
+}
@@ -0,0 +1,91 @@
+package dockerclient
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func tarFileNames(t *testing.T, dir string, dockerfile string) []string {
+	t.Helper()
+	buildContext, err := tarBuildContext(dir, dockerfile)
+	if err != nil {
+		t.Fatalf("tarBuildContext() error = %v", err)
+	}
+	defer buildContext.Close()
+	var names []string
+	reader := tar.NewReader(buildContext)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			names = append(names, header.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestTarBuildContextHonoursDockerignore(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".dockerignore":                  "# build output\n**/*.log\nnode_modules\ntarget\n!target/app.jar\nDockerfile\n.dockerignore\n",
+		"Dockerfile":                     "FROM scratch\n",
+		"src/main.go":                    "package main\n",
+		"src/debug.log":                  "noise\n",
+		"debug.log":                      "noise\n",
+		"target/app.jar":                 "jar\n",
+		"target/classes/App.class":       "class\n",
+		"node_modules/left-pad/index.js": "module.exports = {}\n",
+	})
+
+	got := tarFileNames(t, dir, "Dockerfile")
+	want := []string{".dockerignore", "Dockerfile", "src/main.go", "target/app.jar"}
+	if !slices.Equal(got, want) {
+		t.Errorf("build context = %v, want %v", got, want)
+	}
+}
+
+func TestTarBuildContextWithoutDockerignore(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Dockerfile":  "FROM scratch\n",
+		"src/main.go": "package main\n",
+	})
+
+	got := tarFileNames(t, dir, "Dockerfile")
+	want := []string{"Dockerfile", "src/main.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("build context = %v, want %v", got, want)
+	}
+}
+
+func TestKeepBuildFiles(t *testing.T) {
+	got := keepBuildFiles([]string{"docker", "*.md"}, "docker/Dockerfile.dev")
+	want := []string{"docker", "*.md", "!docker/Dockerfile.dev"}
+	if !slices.Equal(got, want) {
+		t.Errorf("keepBuildFiles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package dockerclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// buildkitTraceID marks the progress messages of a BuildKit build. Their Aux holds a base64 encoded StatusResponse
+// of BuildKit's control API instead of the Stream lines the legacy builder sends.
+const buildkitTraceID = "moby.buildkit.trace"
+
+// field numbers in BuildKit's control.proto of the parts of a StatusResponse the build output needs
+const (
+	statusVertexesField  protowire.Number = 1
+	statusLogsField      protowire.Number = 3
+	vertexNameField      protowire.Number = 3
+	vertexCachedField    protowire.Number = 4
+	vertexCompletedField protowire.Number = 6
+	vertexErrorField     protowire.Number = 7
+	vertexLogMsgField    protowire.Number = 4
+)
+
+// buildkitProgress passes build progress on to onProgress with BuildKit traces turned into Stream lines, so a
+// BuildKit build reports its output the same way a legacy build does
+func buildkitProgress(onProgress ProgressFunc) ProgressFunc {
+	if onProgress == nil {
+		return nil
+	}
+	return func(message jsonmessage.JSONMessage) {
+		if message.ID != buildkitTraceID || message.Aux == nil {
+			onProgress(message)
+			return
+		}
+		output, err := buildkitTraceOutput(*message.Aux)
+		if err != nil {
+			slog.With(slog.Any("error", err)).Debug("Failed to decode buildkit trace")
+			return
+		}
+		if output != "" {
+			onProgress(jsonmessage.JSONMessage{Stream: output})
+		}
+	}
+}
+
+// buildkitTraceOutput returns the output lines of a BuildKit trace: a line for each step that finished, was cached or
+// failed, then what the steps logged. Steps are sent again on every change while they run, so only finished ones
+// are listed to print each once.
+func buildkitTraceOutput(aux json.RawMessage) (string, error) {
+	var trace []byte
+	err := json.Unmarshal(aux, &trace)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode buildkit trace: %w", err)
+	}
+	var steps, logs strings.Builder
+	err = eachField(trace, func(num protowire.Number, value []byte, _ uint64) error {
+		switch num {
+		case statusVertexesField:
+			line, err := vertexLine(value)
+			steps.WriteString(line)
+			return err
+		case statusLogsField:
+			return eachField(value, func(num protowire.Number, value []byte, _ uint64) error {
+				if num == vertexLogMsgField {
+					logs.Write(value)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to parse buildkit trace: %w", err)
+	}
+	return steps.String() + logs.String(), nil
+}
+
+func vertexLine(vertex []byte) (string, error) {
+	var name, vertexErr string
+	var cached, completed bool
+	err := eachField(vertex, func(num protowire.Number, value []byte, varint uint64) error {
+		switch num {
+		case vertexNameField:
+			name = string(value)
+		case vertexCachedField:
+			cached = varint != 0
+		case vertexCompletedField:
+			completed = true
+		case vertexErrorField:
+			vertexErr = string(value)
+		}
+		return nil
+	})
+	switch {
+	case err != nil || !completed:
+		return "", err
+	case vertexErr != "":
+		return fmt.Sprintf("%s ERROR: %s\n", name, vertexErr), nil
+	case cached:
+		return name + " CACHED\n", nil
+	}
+	return name + " DONE\n", nil
+}
+
+// eachField calls fn with each field of a protobuf message: the bytes of length-delimited fields and the value of
+// varints. Other fields are skipped.
+func eachField(message []byte, fn func(num protowire.Number, value []byte, varint uint64) error) error {
+	for len(message) > 0 {
+		num, typ, n := protowire.ConsumeTag(message)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		message = message[n:]
+		var err error
+		switch typ {
+		case protowire.BytesType:
+			var value []byte
+			value, n = protowire.ConsumeBytes(message)
+			if n >= 0 {
+				err = fn(num, value, 0)
+			}
+		case protowire.VarintType:
+			var varint uint64
+			varint, n = protowire.ConsumeVarint(message)
+			if n >= 0 {
+				err = fn(num, nil, varint)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, message)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err != nil {
+			return err
+		}
+		message = message[n:]
+	}
+	return nil
+}
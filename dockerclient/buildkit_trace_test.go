@@ -0,0 +1,112 @@
+package dockerclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendBytesField(message []byte, num protowire.Number, value []byte) []byte {
+	message = protowire.AppendTag(message, num, protowire.BytesType)
+	return protowire.AppendBytes(message, value)
+}
+
+// vertex encodes a Vertex with a digest, which the output doesn't use, and the given state
+func vertex(name string, cached bool, completed bool, vertexErr string) []byte {
+	message := appendBytesField(nil, 1, []byte("sha256:abc"))
+	message = appendBytesField(message, vertexNameField, []byte(name))
+	if cached {
+		message = protowire.AppendTag(message, vertexCachedField, protowire.VarintType)
+		message = protowire.AppendVarint(message, 1)
+	}
+	// a Timestamp with only seconds set
+	timestamp := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1700000000)
+	message = appendBytesField(message, 5, timestamp)
+	if completed {
+		message = appendBytesField(message, vertexCompletedField, timestamp)
+	}
+	if vertexErr != "" {
+		message = appendBytesField(message, vertexErrorField, []byte(vertexErr))
+	}
+	return message
+}
+
+func vertexLog(msg string) []byte {
+	message := appendBytesField(nil, 1, []byte("sha256:abc"))
+	message = protowire.AppendTag(message, 3, protowire.VarintType)
+	message = protowire.AppendVarint(message, 1)
+	return appendBytesField(message, vertexLogMsgField, []byte(msg))
+}
+
+// traceAux encodes a StatusResponse with the given vertexes and logs the way the build API sends it
+func traceAux(t *testing.T, vertexes [][]byte, logs [][]byte) json.RawMessage {
+	t.Helper()
+	var trace []byte
+	for _, v := range vertexes {
+		trace = appendBytesField(trace, statusVertexesField, v)
+	}
+	for _, l := range logs {
+		trace = appendBytesField(trace, statusLogsField, l)
+	}
+	aux, err := json.Marshal(trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aux
+}
+
+func TestBuildkitTraceOutput(t *testing.T) {
+	aux := traceAux(t, [][]byte{
+		vertex("[1/3] FROM node:20", true, true, ""),
+		vertex("[2/3] RUN npm ci", false, false, ""),
+		vertex("[3/3] COPY . .", false, true, ""),
+		vertex("[4/4] RUN npm test", false, true, "exit code: 1"),
+	}, [][]byte{vertexLog("added 120 packages\n")})
+
+	output, err := buildkitTraceOutput(aux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[1/3] FROM node:20 CACHED\n" +
+		"[3/3] COPY . . DONE\n" +
+		"[4/4] RUN npm test ERROR: exit code: 1\n" +
+		"added 120 packages\n"
+	if output != want {
+		t.Errorf("buildkitTraceOutput() = %q, want %q", output, want)
+	}
+}
+
+func TestBuildkitTraceOutputRejectsTruncatedTrace(t *testing.T) {
+	aux := traceAux(t, [][]byte{vertex("[1/1] FROM alpine", false, true, "")}, nil)
+	var trace []byte
+	if err := json.Unmarshal(aux, &trace); err != nil {
+		t.Fatal(err)
+	}
+	truncated, err := json.Marshal(trace[:len(trace)-3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := buildkitTraceOutput(truncated); err == nil {
+		t.Error("buildkitTraceOutput() of a truncated trace succeeded, want an error")
+	}
+}
+
+func TestBuildkitProgressPassesOtherMessagesOn(t *testing.T) {
+	var received []jsonmessage.JSONMessage
+	onProgress := buildkitProgress(func(message jsonmessage.JSONMessage) {
+		received = append(received, message)
+	})
+
+	onProgress(jsonmessage.JSONMessage{Stream: "Step 1/3\n"})
+	aux := traceAux(t, nil, [][]byte{vertexLog("hello\n")})
+	onProgress(jsonmessage.JSONMessage{ID: buildkitTraceID, Aux: &aux})
+	// a trace of steps that are still running has no output
+	aux = traceAux(t, [][]byte{vertex("[1/1] RUN make", false, false, "")}, nil)
+	onProgress(jsonmessage.JSONMessage{ID: buildkitTraceID, Aux: &aux})
+
+	if len(received) != 2 || received[0].Stream != "Step 1/3\n" || received[1].Stream != "hello\n" {
+		t.Errorf("received %+v, want the plain message and the trace's log line", received)
+	}
+}
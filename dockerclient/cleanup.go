@@ -0,0 +1,60 @@
+package dockerclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/volume"
+)
+
+type DiskUsage struct {
+	// ContainerBytes maps container IDs to the size of their writable layer
+	ContainerBytes map[string]int64
+	Volumes        []*volume.Volume
+}
+
+// GetDiskUsage returns the disk used by containers and volumes. Volume sizes and ref counts are in their UsageData.
+func GetDiskUsage(ctx context.Context) (DiskUsage, error) {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("failed to get docker client: %w", err)
+	}
+	usage, err := dockerClient.DiskUsage(ctx, types.DiskUsageOptions{
+		Types: []types.DiskUsageObject{types.ContainerObject, types.VolumeObject},
+	})
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+	result := DiskUsage{ContainerBytes: map[string]int64{}, Volumes: usage.Volumes}
+	for _, c := range usage.Containers {
+		result.ContainerBytes[c.ID] = c.SizeRw
+	}
+	return result, nil
+}
+
+// RemoveContainer removes a stopped container, and its anonymous volumes when removeVolumes is set
+func RemoveContainer(ctx context.Context, containerID string, removeVolumes bool) error {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("failed to get docker client: %w", err)
+	}
+	err = dockerClient.ContainerRemove(ctx, containerID, container.RemoveOptions{RemoveVolumes: removeVolumes})
+	if err != nil {
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+	return nil
+}
+
+func RemoveVolume(ctx context.Context, volumeName string) error {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("failed to get docker client: %w", err)
+	}
+	err = dockerClient.VolumeRemove(ctx, volumeName, false)
+	if err != nil {
+		return fmt.Errorf("failed to remove volume: %w", err)
+	}
+	return nil
+}
@@ -14,9 +14,12 @@ import (
 
 var defaultClient *client.Client
 
+// DefaultClient returns the client for the endpoint set with Configure
 func DefaultClient() (*client.Client, error) {
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
 	if defaultClient == nil {
-		c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		c, err := newClient(endpoint)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create docker client: %w", err)
 		}
@@ -28,6 +31,8 @@ func DefaultClient() (*client.Client, error) {
 
 var ErrNoContainerFound = fmt.Errorf("no container found")
 
+const stopTimeoutSeconds = 10
+
 func GetContainer(ctx context.Context, containerName string) (container.Summary, error) {
 	dockerClient, err := DefaultClient()
 	if err != nil {
@@ -104,3 +109,30 @@ func StopContainer(ctx context.Context, containerName string) error {
 	}
 	return nil
 }
+
+// StopContainerByID asks the container to stop and kills it if it hasn't exited after stopTimeoutSeconds. Unlike
+// StopContainer, which repos use, it gives the container a chance to shut down cleanly.
+func StopContainerByID(ctx context.Context, containerID string) error {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("failed to get docker client: %w", err)
+	}
+	timeout := stopTimeoutSeconds
+	err = dockerClient.ContainerStop(ctx, containerID, container.StopOptions{Timeout: &timeout})
+	if err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	return nil
+}
+
+func StartContainerByID(ctx context.Context, containerID string) error {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("failed to get docker client: %w", err)
+	}
+	err = dockerClient.ContainerStart(ctx, containerID, container.StartOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package dockerclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+const defaultContextName = "default"
+
+var (
+	ErrContextNotFound     = errors.New("docker context not found")
+	ErrUnsupportedEndpoint = errors.New("unsupported docker endpoint")
+)
+
+// Endpoint picks the daemon to talk to. Host takes a socket path or a unix://, tcp://, http(s):// or npipe:// URL,
+// Context the name of a docker CLI context. With neither set DOCKER_HOST is used if set, then the CLI's current
+// context.
+type Endpoint struct {
+	Host    string `json:"host"`
+	Context string `json:"context"`
+}
+
+type DockerContext struct {
+	Name string `json:"name"`
+	// Host is empty for the default context, which uses the platform's default socket
+	Host    string `json:"host"`
+	Current bool   `json:"current"`
+}
+
+type ConnectionInfo struct {
+	Host          string `json:"host"`
+	ServerVersion string `json:"serverVersion"`
+	APIVersion    string `json:"apiVersion"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+}
+
+var (
+	clientMutex sync.Mutex
+	endpoint    Endpoint
+)
+
+// Configure switches the endpoint DefaultClient connects to. The client is rebuilt on its next use.
+func Configure(newEndpoint Endpoint) error {
+	_, _, err := resolveEndpoint(newEndpoint)
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+	endpoint = newEndpoint
+	dropClient()
+	return err
+}
+
+// Reconnect drops the current client so the next call connects and negotiates the API version again, e.g. after the
+// daemon restarted with a different version
+func Reconnect() {
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+	dropClient()
+}
+
+func dropClient() {
+	if defaultClient != nil {
+		_ = defaultClient.Close()
+		defaultClient = nil
+	}
+}
+
+func newClient(e Endpoint) (*client.Client, error) {
+	host, tlsDir, err := resolveEndpoint(e)
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+	if tlsDir != "" {
+		opts = append(opts, client.WithTLSClientConfig(
+			filepath.Join(tlsDir, "ca.pem"),
+			filepath.Join(tlsDir, "cert.pem"),
+			filepath.Join(tlsDir, "key.pem"),
+		))
+	}
+	return client.NewClientWithOpts(opts...)
+}
+
+// resolveEndpoint works out the host URL and TLS dir for the endpoint. An empty host leaves it to DOCKER_HOST or the
+// platform default.
+func resolveEndpoint(e Endpoint) (string, string, error) {
+	if e.Host != "" {
+		host, err := NormalizeHost(e.Host)
+		return host, "", err
+	}
+	contextName := e.Context
+	if contextName == "" {
+		if os.Getenv(client.EnvOverrideHost) != "" {
+			return "", "", nil
+		}
+		contextName = currentContextName()
+	}
+	if contextName == "" || contextName == defaultContextName {
+		return "", "", nil
+	}
+	meta, err := readContextMeta(contextName)
+	if err != nil {
+		return "", "", err
+	}
+	host, err := NormalizeHost(meta.Host)
+	if err != nil {
+		return "", "", fmt.Errorf("context '%s': %w", contextName, err)
+	}
+	tlsDir := filepath.Join(dockerConfigDir(), "contexts", "tls", contextDirName(contextName), "docker")
+	if _, err := os.Stat(tlsDir); err != nil {
+		tlsDir = ""
+	}
+	return host, tlsDir, nil
+}
+
+// NormalizeHost turns a socket path into a unix:// URL and checks the URL has a scheme the client can dial
+func NormalizeHost(host string) (string, error) {
+	if strings.HasPrefix(host, "/") {
+		return "unix://" + host, nil
+	}
+	scheme, _, ok := strings.Cut(host, "://")
+	if !ok || !slices.Contains([]string{"unix", "tcp", "http", "https", "npipe"}, scheme) {
+		return "", fmt.Errorf("%w: '%s'", ErrUnsupportedEndpoint, host)
+	}
+	return host, nil
+}
+
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".docker")
+}
+
+// currentContextName reads the context the docker CLI uses, from DOCKER_CONTEXT or config.json
+func currentContextName() string {
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name
+	}
+	content, err := os.ReadFile(filepath.Join(dockerConfigDir(), "config.json"))
+	if err != nil {
+		return ""
+	}
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if json.Unmarshal(content, &config) != nil {
+		return ""
+	}
+	return config.CurrentContext
+}
+
+// contextDirName is the name of a context's dirs under contexts/meta and contexts/tls
+func contextDirName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+type contextMeta struct {
+	Name string
+	Host string
+}
+
+func readContextMeta(name string) (contextMeta, error) {
+	content, err := os.ReadFile(filepath.Join(dockerConfigDir(), "contexts", "meta", contextDirName(name), "meta.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return contextMeta{}, fmt.Errorf("%w: '%s'", ErrContextNotFound, name)
+	}
+	if err != nil {
+		return contextMeta{}, fmt.Errorf("failed to read docker context: %w", err)
+	}
+	return parseContextMeta(content)
+}
+
+func parseContextMeta(content []byte) (contextMeta, error) {
+	var meta struct {
+		Name      string
+		Endpoints map[string]struct {
+			Host string
+		}
+	}
+	err := json.Unmarshal(content, &meta)
+	if err != nil {
+		return contextMeta{}, fmt.Errorf("failed to parse docker context: %w", err)
+	}
+	return contextMeta{Name: meta.Name, Host: meta.Endpoints["docker"].Host}, nil
+}
+
+// ListContexts lists the docker CLI's contexts, starting with the default one
+func ListContexts() ([]DockerContext, error) {
+	current := currentContextName()
+	contexts := []DockerContext{{Name: defaultContextName, Current: current == "" || current == defaultContextName}}
+	metaDir := filepath.Join(dockerConfigDir(), "contexts", "meta")
+	entries, err := os.ReadDir(metaDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return contexts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docker contexts: %w", err)
+	}
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(metaDir, entry.Name(), "meta.json"))
+		if err != nil {
+			continue
+		}
+		meta, err := parseContextMeta(content)
+		if err != nil || meta.Name == "" {
+			continue
+		}
+		contexts = append(contexts, DockerContext{Name: meta.Name, Host: meta.Host, Current: meta.Name == current})
+	}
+	slices.SortFunc(contexts[1:], func(a, b DockerContext) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return contexts, nil
+}
+
+// TestConnection connects to the endpoint without switching to it and reports the daemon's version
+func TestConnection(ctx context.Context, e Endpoint) (ConnectionInfo, error) {
+	testClient, err := newClient(e)
+	if err != nil {
+		return ConnectionInfo{}, fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer testClient.Close()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	version, err := testClient.ServerVersion(ctx)
+	if err != nil {
+		return ConnectionInfo{}, fmt.Errorf("failed to connect to docker: %w", err)
+	}
+	return ConnectionInfo{
+		Host:          testClient.DaemonHost(),
+		ServerVersion: version.Version,
+		APIVersion:    version.APIVersion,
+		OS:            version.Os,
+		Arch:          version.Arch,
+	}, nil
+}
+
+// Ping checks the daemon of the configured endpoint is reachable
+func Ping(ctx context.Context) error {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("failed to get docker client: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	_, err = dockerClient.Ping(ctx)
+	return err
+}
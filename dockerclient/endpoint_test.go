@@ -0,0 +1,166 @@
+package dockerclient
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeContext lays a context out under DOCKER_CONFIG the way the docker CLI does
+func writeContext(t *testing.T, configDir string, name string, host string, withTLS bool) {
+	t.Helper()
+	metaDir := filepath.Join(configDir, "contexts", "meta", contextDirName(name))
+	if err := os.MkdirAll(metaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	meta := `{"Name":"` + name + `","Metadata":{},"Endpoints":{"docker":{"Host":"` + host + `","SkipTLSVerify":false}}}`
+	if err := os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if withTLS {
+		tlsDir := filepath.Join(configDir, "contexts", "tls", contextDirName(name), "docker")
+		if err := os.MkdirAll(tlsDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func setupDockerConfig(t *testing.T, currentContext string) string {
+	t.Helper()
+	configDir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", configDir)
+	t.Setenv("DOCKER_CONTEXT", "")
+	t.Setenv("DOCKER_HOST", "")
+	if currentContext != "" {
+		config := `{"auths":{},"currentContext":"` + currentContext + `"}`
+		if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return configDir
+}
+
+func TestContextDirNameMatchesDockerCLI(t *testing.T) {
+	// the docker CLI stores the default context's metadata under this digest
+	want := "37a8eec1ce19687d132fe29051dca629d164e2c4958ba141d5f4133a33f0688f"
+	if got := contextDirName("default"); got != want {
+		t.Errorf("contextDirName(default) = %s, want %s", got, want)
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{"/var/run/docker.sock", "unix:///var/run/docker.sock", false},
+		{"unix:///Users/me/.colima/default/docker.sock", "unix:///Users/me/.colima/default/docker.sock", false},
+		{"tcp://192.168.64.2:2376", "tcp://192.168.64.2:2376", false},
+		{"npipe:////./pipe/docker_engine", "npipe:////./pipe/docker_engine", false},
+		{"ssh://me@build-host", "", true},
+		{"localhost:2375", "", true},
+		{"", "", true},
+	}
+	for _, test := range tests {
+		got, err := NormalizeHost(test.host)
+		if (err != nil) != test.wantErr {
+			t.Errorf("NormalizeHost(%q) error = %v, wantErr %t", test.host, err, test.wantErr)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrUnsupportedEndpoint) {
+			t.Errorf("NormalizeHost(%q) error = %v, want ErrUnsupportedEndpoint", test.host, err)
+		}
+		if got != test.want {
+			t.Errorf("NormalizeHost(%q) = %q, want %q", test.host, got, test.want)
+		}
+	}
+}
+
+func TestParseContextMeta(t *testing.T) {
+	content := []byte(`{"Name":"colima","Metadata":{"Description":"colima"},"Endpoints":{"docker":{"Host":"unix:///Users/me/.colima/default/docker.sock","SkipTLSVerify":false}}}`)
+	meta, err := parseContextMeta(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Name != "colima" || meta.Host != "unix:///Users/me/.colima/default/docker.sock" {
+		t.Errorf("parseContextMeta() = %+v", meta)
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	configDir := setupDockerConfig(t, "colima")
+	writeContext(t, configDir, "colima", "unix:///Users/me/.colima/default/docker.sock", false)
+	writeContext(t, configDir, "remote", "tcp://10.0.0.5:2376", true)
+
+	tests := []struct {
+		desc     string
+		endpoint Endpoint
+		wantHost string
+		wantTLS  bool
+		wantErr  error
+	}{
+		{"explicit host wins over contexts", Endpoint{Host: "/var/run/docker.sock", Context: "remote"}, "unix:///var/run/docker.sock", false, nil},
+		{"current context from config.json", Endpoint{}, "unix:///Users/me/.colima/default/docker.sock", false, nil},
+		{"named context with TLS", Endpoint{Context: "remote"}, "tcp://10.0.0.5:2376", true, nil},
+		{"default context uses the platform socket", Endpoint{Context: "default"}, "", false, nil},
+		{"missing context", Endpoint{Context: "gone"}, "", false, ErrContextNotFound},
+	}
+	for _, test := range tests {
+		host, tlsDir, err := resolveEndpoint(test.endpoint)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("%s: error = %v, want %v", test.desc, err, test.wantErr)
+			continue
+		}
+		if host != test.wantHost {
+			t.Errorf("%s: host = %q, want %q", test.desc, host, test.wantHost)
+		}
+		if (tlsDir != "") != test.wantTLS {
+			t.Errorf("%s: tlsDir = %q, want TLS %t", test.desc, tlsDir, test.wantTLS)
+		}
+	}
+}
+
+func TestResolveEndpointPrefersDockerEnv(t *testing.T) {
+	configDir := setupDockerConfig(t, "colima")
+	writeContext(t, configDir, "colima", "unix:///Users/me/.colima/default/docker.sock", false)
+	writeContext(t, configDir, "remote", "tcp://10.0.0.5:2376", false)
+
+	t.Setenv("DOCKER_CONTEXT", "remote")
+	host, _, err := resolveEndpoint(Endpoint{})
+	if err != nil || host != "tcp://10.0.0.5:2376" {
+		t.Errorf("with DOCKER_CONTEXT: resolveEndpoint() = %q, %v", host, err)
+	}
+
+	t.Setenv("DOCKER_HOST", "tcp://127.0.0.1:2375")
+	host, _, err = resolveEndpoint(Endpoint{})
+	if err != nil || host != "" {
+		t.Errorf("with DOCKER_HOST: resolveEndpoint() = %q, %v, want it left to DOCKER_HOST", host, err)
+	}
+}
+
+func TestListContexts(t *testing.T) {
+	configDir := setupDockerConfig(t, "remote")
+	writeContext(t, configDir, "remote", "tcp://10.0.0.5:2376", false)
+	writeContext(t, configDir, "colima", "unix:///Users/me/.colima/default/docker.sock", false)
+
+	contexts, err := ListContexts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names, current []string
+	for _, c := range contexts {
+		names = append(names, c.Name)
+		if c.Current {
+			current = append(current, c.Name)
+		}
+	}
+	if want := []string{"default", "colima", "remote"}; !slices.Equal(names, want) {
+		t.Errorf("ListContexts() names = %v, want %v", names, want)
+	}
+	if want := []string{"remote"}; !slices.Equal(current, want) {
+		t.Errorf("ListContexts() current = %v, want %v", current, want)
+	}
+}
@@ -0,0 +1,205 @@
+package dockerclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+var ErrContainerNotRunning = errors.New("container is not running")
+
+type ExecOptions struct {
+	Cmd []string
+	Env []string
+	// Stdin is copied to the command's stdin when set, which is closed once it is drained
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+type ExecResult struct {
+	Output   string `json:"output"`
+	ExitCode int    `json:"exitCode"`
+}
+
+func runningContainerID(ctx context.Context, containerName string) (string, error) {
+	c, err := GetContainer(ctx, containerName)
+	if errors.Is(err, ErrNoContainerFound) {
+		return "", fmt.Errorf("%w: '%s'", ErrContainerNotRunning, containerName)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get container: %w", err)
+	}
+	return c.ID, nil
+}
+
+// Exec runs a command in the running container without a TTY, waits for it to finish and returns its exit code
+func Exec(ctx context.Context, containerName string, opts ExecOptions) (int, error) {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get docker client: %w", err)
+	}
+	id, err := runningContainerID(ctx, containerName)
+	if err != nil {
+		return 0, err
+	}
+	created, err := dockerClient.ContainerExecCreate(ctx, id, container.ExecOptions{
+		Cmd:          opts.Cmd,
+		Env:          opts.Env,
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create exec: %w", err)
+	}
+	attached, err := dockerClient.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer attached.Close()
+	// reading the hijacked connection doesn't watch the context, so it is closed to interrupt the read. Docker has no
+	// way to kill an exec, so the command itself may keep running in the container.
+	stopClosing := context.AfterFunc(ctx, attached.Close)
+	defer stopClosing()
+
+	if opts.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(attached.Conn, opts.Stdin)
+			_ = attached.CloseWrite()
+		}()
+	}
+	stdout, stderr := opts.Stdout, opts.Stderr
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+	_, err = stdcopy.StdCopy(stdout, stderr, attached.Reader)
+	if ctx.Err() != nil {
+		return 0, fmt.Errorf("exec did not finish: %w", ctx.Err())
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read exec output: %w", err)
+	}
+	inspection, err := dockerClient.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	return inspection.ExitCode, nil
+}
+
+// ExecCombined runs a command in the running container and returns its stdout and stderr interleaved
+func ExecCombined(ctx context.Context, containerName string, cmd []string) (ExecResult, error) {
+	output := &lockedBuffer{}
+	exitCode, err := Exec(ctx, containerName, ExecOptions{Cmd: cmd, Stdout: output, Stderr: output})
+	if err != nil {
+		return ExecResult{}, err
+	}
+	return ExecResult{Output: output.String(), ExitCode: exitCode}, nil
+}
+
+type lockedBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (this *lockedBuffer) Write(p []byte) (int, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.buf.Write(p)
+}
+
+func (this *lockedBuffer) String() string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.buf.String()
+}
+
+// ExecSession is an interactive command running in a container with a TTY attached
+type ExecSession struct {
+	ctx      context.Context
+	execID   string
+	attached types.HijackedResponse
+}
+
+// OpenExecSession starts an interactive command in the running container with a TTY of the given size
+func OpenExecSession(ctx context.Context, containerName string, cmd []string, rows uint, cols uint) (*ExecSession, error) {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get docker client: %w", err)
+	}
+	id, err := runningContainerID(ctx, containerName)
+	if err != nil {
+		return nil, err
+	}
+	consoleSize := &[2]uint{rows, cols}
+	created, err := dockerClient.ContainerExecCreate(ctx, id, container.ExecOptions{
+		Cmd:          cmd,
+		Tty:          true,
+		ConsoleSize:  consoleSize,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          []string{"TERM=xterm-256color"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec: %w", err)
+	}
+	attached, err := dockerClient.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{
+		Tty:         true,
+		ConsoleSize: consoleSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	return &ExecSession{ctx: ctx, execID: created.ID, attached: attached}, nil
+}
+
+// Read reads the TTY output. It returns io.EOF once the command exits or the session is closed.
+func (this *ExecSession) Read(p []byte) (int, error) {
+	return this.attached.Reader.Read(p)
+}
+
+// Write sends input to the TTY
+func (this *ExecSession) Write(p []byte) (int, error) {
+	return this.attached.Conn.Write(p)
+}
+
+func (this *ExecSession) Resize(rows uint, cols uint) error {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("failed to get docker client: %w", err)
+	}
+	err = dockerClient.ContainerExecResize(this.ctx, this.execID, container.ResizeOptions{Height: rows, Width: cols})
+	if err != nil {
+		return fmt.Errorf("failed to resize exec: %w", err)
+	}
+	return nil
+}
+
+// ExitCode returns the exit code of the command, once it has exited
+func (this *ExecSession) ExitCode() (int, error) {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get docker client: %w", err)
+	}
+	inspection, err := dockerClient.ContainerExecInspect(this.ctx, this.execID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	return inspection.ExitCode, nil
+}
+
+// Close detaches from the session. Commands like shells exit when their TTY is closed.
+func (this *ExecSession) Close() {
+	this.attached.Close()
+}
@@ -0,0 +1,153 @@
+package dockerclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// ProgressFunc receives each message of a pull or build progress stream
+type ProgressFunc func(message jsonmessage.JSONMessage)
+
+type ImageInfo struct {
+	ID        string    `json:"id"`
+	Tags      []string  `json:"tags"`
+	Created   time.Time `json:"created"`
+	SizeBytes int64     `json:"sizeBytes"`
+}
+
+type PruneReport struct {
+	ImagesDeleted  int    `json:"imagesDeleted"`
+	SpaceReclaimed uint64 `json:"spaceReclaimed"`
+}
+
+var ErrNoImageFound = errors.New("no image found")
+
+func InspectImage(ctx context.Context, imageRef string) (ImageInfo, error) {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("failed to get docker client: %w", err)
+	}
+	inspection, err := dockerClient.ImageInspect(ctx, imageRef)
+	if client.IsErrNotFound(err) {
+		return ImageInfo{}, fmt.Errorf("%w: '%s'", ErrNoImageFound, imageRef)
+	}
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("failed to inspect image: %w", err)
+	}
+	info := ImageInfo{ID: inspection.ID, Tags: inspection.RepoTags, SizeBytes: inspection.Size}
+	if info.Tags == nil {
+		info.Tags = []string{}
+	}
+	info.Created, _ = time.Parse(time.RFC3339Nano, inspection.Created)
+	return info, nil
+}
+
+// streamProgress passes each message of the stream on and returns the error reported in it, if any
+func streamProgress(body io.Reader, onProgress ProgressFunc) error {
+	decoder := json.NewDecoder(body)
+	for {
+		var message jsonmessage.JSONMessage
+		err := decoder.Decode(&message)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read progress: %w", err)
+		}
+		if onProgress != nil {
+			onProgress(message)
+		}
+		if message.Error != nil {
+			return message.Error
+		}
+	}
+}
+
+func PullImage(ctx context.Context, imageRef string, onProgress ProgressFunc) error {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("failed to get docker client: %w", err)
+	}
+	body, err := dockerClient.ImagePull(ctx, imageRef, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	defer body.Close()
+	err = streamProgress(body, onProgress)
+	if err != nil {
+		return fmt.Errorf("failed to pull image '%s': %w", imageRef, err)
+	}
+	return nil
+}
+
+type BuildOptions struct {
+	// Dir is the build context, Dockerfile is relative to it
+	Dir        string
+	Dockerfile string
+	// BuildArgs and Target are passed as with --build-arg and --target
+	BuildArgs map[string]*string
+	Target    string
+	Tags      []string
+	Labels    map[string]string
+	NoCache   bool
+}
+
+// BuildImage builds with BuildKit, as `docker build` and `docker compose build` do, so Dockerfiles that rely on it,
+// e.g. with RUN --mount or heredocs, build the same as from the CLI. Unlike the CLI the build has no client session:
+// build secrets, SSH forwarding and registry credentials are not available to it, so base images must be public or
+// already pulled. Progress is reported as Stream lines like a legacy build's.
+func BuildImage(ctx context.Context, opts BuildOptions, onProgress ProgressFunc) error {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("failed to get docker client: %w", err)
+	}
+	buildContext, err := tarBuildContext(opts.Dir, opts.Dockerfile)
+	if err != nil {
+		return err
+	}
+	defer buildContext.Close()
+	response, err := dockerClient.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Dockerfile: opts.Dockerfile,
+		BuildArgs:  opts.BuildArgs,
+		Target:     opts.Target,
+		Tags:       opts.Tags,
+		Labels:     opts.Labels,
+		NoCache:    opts.NoCache,
+		Version:    types.BuilderBuildKit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build image: %w", err)
+	}
+	defer response.Body.Close()
+	err = streamProgress(response.Body, buildkitProgress(onProgress))
+	if err != nil {
+		return fmt.Errorf("failed to build image: %w", err)
+	}
+	return nil
+}
+
+// PruneDanglingImages removes untagged images carrying the label
+func PruneDanglingImages(ctx context.Context, label string) (PruneReport, error) {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return PruneReport{}, fmt.Errorf("failed to get docker client: %w", err)
+	}
+	report, err := dockerClient.ImagesPrune(ctx, filters.NewArgs(
+		filters.Arg("dangling", "true"),
+		filters.Arg("label", label),
+	))
+	if err != nil {
+		return PruneReport{}, fmt.Errorf("failed to prune images: %w", err)
+	}
+	return PruneReport{ImagesDeleted: len(report.ImagesDeleted), SpaceReclaimed: report.SpaceReclaimed}, nil
+}
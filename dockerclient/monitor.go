@@ -0,0 +1,82 @@
+package dockerclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"phaas-localservices-ui/scheduler"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	DockerAvailableEvent = "docker-available"
+
+	healthCheckJobName = "docker-health"
+	healthCheckPeriod  = 5 * time.Second
+)
+
+var ErrDockerUnavailable = errors.New("docker is not available")
+
+type AvailabilityListener func(available bool)
+
+// Monitor pings the daemon and tracks whether it is reachable, so polling can pause while it isn't. It is bound to the
+// frontend, so its exported methods are only the ones the frontend may call; the Go side sets it up through package
+// functions.
+type Monitor struct {
+	ctx context.Context
+
+	mutex     sync.Mutex
+	available bool
+	listeners []AvailabilityListener
+}
+
+func NewMonitor() *Monitor {
+	return &Monitor{available: true}
+}
+
+// AddAvailabilityListener registers a listener called when the daemon becomes unavailable or available again
+func AddAvailabilityListener(monitor *Monitor, listener AvailabilityListener) {
+	monitor.listeners = append(monitor.listeners, listener)
+}
+
+// StartMonitor checks the daemon right away, then keeps checking it in the background
+func StartMonitor(monitor *Monitor, ctx context.Context, jobScheduler *scheduler.Scheduler) error {
+	monitor.ctx = ctx
+	monitor.check()
+	return jobScheduler.AddJob(healthCheckJobName, healthCheckPeriod, monitor.check)
+}
+
+func (this *Monitor) IsDockerAvailable() bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.available
+}
+
+// check pings the daemon and, when its availability changes, tells the Go listeners and then the frontend. A daemon
+// that comes back may have restarted with a different version, so the client reconnects first.
+func (this *Monitor) check() {
+	err := Ping(this.ctx)
+	available := err == nil
+
+	this.mutex.Lock()
+	changed := available != this.available
+	this.available = available
+	this.mutex.Unlock()
+	if !changed {
+		return
+	}
+
+	if available {
+		Reconnect()
+		slog.InfoContext(this.ctx, "Docker is available again")
+	} else {
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Docker is not available, pausing status polling")
+	}
+	for _, listener := range this.listeners {
+		listener(available)
+	}
+	runtime.EventsEmit(this.ctx, DockerAvailableEvent, available)
+}
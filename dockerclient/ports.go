@@ -0,0 +1,93 @@
+package dockerclient
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// InspectAnyContainer inspects the named container whether or not it is running. It returns nil when there is no
+// such container.
+func InspectAnyContainer(ctx context.Context, containerName string) (*container.InspectResponse, error) {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get docker client: %w", err)
+	}
+	containers, err := dockerClient.ContainerList(ctx, container.ListOptions{All: true, Filters: filters.NewArgs(
+		filters.Arg("name", containerName),
+	)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, c := range containers {
+		if !slices.Contains(c.Names, "/"+containerName) {
+			continue
+		}
+		inspection, err := dockerClient.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect container: %w", err)
+		}
+		return &inspection, nil
+	}
+	return nil, nil
+}
+
+// PublishedPorts returns the host ports the container publishes, or is configured to publish when it isn't running
+func PublishedPorts(inspection *container.InspectResponse) []uint16 {
+	var ports []uint16
+	addPort := func(hostPort string) {
+		port, err := strconv.ParseUint(hostPort, 10, 16)
+		if err == nil && port != 0 && !slices.Contains(ports, uint16(port)) {
+			ports = append(ports, uint16(port))
+		}
+	}
+	if inspection.NetworkSettings != nil {
+		for _, bindings := range inspection.NetworkSettings.Ports {
+			for _, binding := range bindings {
+				addPort(binding.HostPort)
+			}
+		}
+	}
+	if inspection.HostConfig != nil {
+		for _, bindings := range inspection.HostConfig.PortBindings {
+			for _, binding := range bindings {
+				addPort(binding.HostPort)
+			}
+		}
+	}
+	slices.Sort(ports)
+	return ports
+}
+
+// FindPortPublisher returns the name of a running container publishing the host port, ignoring the excluded
+// container names
+func FindPortPublisher(ctx context.Context, port uint16, excluded []string) (string, bool, error) {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get docker client: %w", err)
+	}
+	containers, err := dockerClient.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, c := range containers {
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		if slices.Contains(excluded, name) {
+			continue
+		}
+		for _, p := range c.Ports {
+			if p.PublicPort == port {
+				return name, true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,235 @@
+package dockerclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+type ContainerMetrics struct {
+	Time             time.Time `json:"time"`
+	CPUPercent       float64   `json:"cpuPercent"`
+	MemoryBytes      uint64    `json:"memoryBytes"`
+	MemoryLimitBytes uint64    `json:"memoryLimitBytes"`
+	MemoryPercent    float64   `json:"memoryPercent"`
+	NetRxBytes       uint64    `json:"netRxBytes"`
+	NetTxBytes       uint64    `json:"netTxBytes"`
+	BlockReadBytes   uint64    `json:"blockReadBytes"`
+	BlockWriteBytes  uint64    `json:"blockWriteBytes"`
+}
+
+type containerStats struct {
+	history []ContainerMetrics
+	// live is set while the stats stream for the container is open
+	live   bool
+	cancel context.CancelFunc
+}
+
+// StatsCollector streams resource usage for a set of containers and keeps a short rolling history for each
+type StatsCollector struct {
+	historySize int
+
+	mutex      sync.Mutex
+	containers map[string]*containerStats
+}
+
+func NewStatsCollector(historySize int) *StatsCollector {
+	return &StatsCollector{
+		historySize: historySize,
+		containers:  map[string]*containerStats{},
+	}
+}
+
+// Sync opens a stats stream for each named container that is running and doesn't have one yet, and closes the
+// streams of containers no longer in the list. Streams end on their own when their container stops, so calling this
+// periodically picks containers up again once they are restarted.
+func (this *StatsCollector) Sync(ctx context.Context, containerNames []string) error {
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("failed to get docker client: %w", err)
+	}
+	running, err := dockerClient.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	runningIDs := map[string]string{}
+	for _, c := range running {
+		for _, name := range c.Names {
+			runningIDs[strings.TrimPrefix(name, "/")] = c.ID
+		}
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	wanted := map[string]bool{}
+	for _, name := range containerNames {
+		wanted[name] = true
+		stats, ok := this.containers[name]
+		if !ok {
+			stats = &containerStats{}
+			this.containers[name] = stats
+		}
+		id, isRunning := runningIDs[name]
+		if stats.live || !isRunning {
+			continue
+		}
+		streamCtx, cancel := context.WithCancel(ctx)
+		stats.live = true
+		stats.cancel = cancel
+		go this.stream(streamCtx, name, id, stats)
+	}
+	for name, stats := range this.containers {
+		if wanted[name] {
+			continue
+		}
+		if stats.cancel != nil {
+			stats.cancel()
+		}
+		delete(this.containers, name)
+	}
+	return nil
+}
+
+// stream records the stats of the container into stats until the stream ends. The container's entry may have been
+// dropped and added again by Sync meanwhile, so stats is used rather than looking the entry up by name.
+func (this *StatsCollector) stream(ctx context.Context, name string, id string, stats *containerStats) {
+	defer this.streamEnded(name, stats)
+
+	logger := slog.With(slog.String("container", name))
+	dockerClient, err := DefaultClient()
+	if err != nil {
+		logger.With(slog.Any("error", err)).ErrorContext(ctx, "Failed to get docker client")
+		return
+	}
+	reader, err := dockerClient.ContainerStats(ctx, id, true)
+	if err != nil {
+		logger.With(slog.Any("error", err)).WarnContext(ctx, "Failed to stream container stats")
+		return
+	}
+	defer reader.Body.Close()
+
+	decoder := json.NewDecoder(reader.Body)
+	for {
+		var response container.StatsResponse
+		err := decoder.Decode(&response)
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.With(slog.Any("error", err)).WarnContext(ctx, "Failed to decode container stats")
+			return
+		}
+		this.record(name, stats, metricsFromStats(response))
+	}
+}
+
+// streamEnded marks the container's stats as no longer live, unless its entry was replaced by one with a newer stream
+func (this *StatsCollector) streamEnded(name string, stats *containerStats) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.containers[name] != stats {
+		return
+	}
+	stats.live = false
+	stats.cancel = nil
+}
+
+func (this *StatsCollector) record(name string, stats *containerStats, metrics ContainerMetrics) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.containers[name] != stats {
+		return
+	}
+	stats.history = append(stats.history, metrics)
+	if len(stats.history) > this.historySize {
+		stats.history = stats.history[len(stats.history)-this.historySize:]
+	}
+}
+
+// Current returns the latest metrics of the container, if its stats are being streamed
+func (this *StatsCollector) Current(name string) (ContainerMetrics, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	stats, ok := this.containers[name]
+	if !ok || !stats.live || len(stats.history) == 0 {
+		return ContainerMetrics{}, false
+	}
+	return stats.history[len(stats.history)-1], true
+}
+
+// History returns the recorded metrics of the container, oldest first. It is kept after the container stops.
+func (this *StatsCollector) History(name string) []ContainerMetrics {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	stats, ok := this.containers[name]
+	if !ok {
+		return []ContainerMetrics{}
+	}
+	history := make([]ContainerMetrics, len(stats.history))
+	copy(history, stats.history)
+	return history
+}
+
+// Close ends all stats streams
+func (this *StatsCollector) Close() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	for _, stats := range this.containers {
+		if stats.cancel != nil {
+			stats.cancel()
+		}
+	}
+}
+
+// metricsFromStats computes usage the same way the docker CLI does for `docker stats`
+func metricsFromStats(stats container.StatsResponse) ContainerMetrics {
+	metrics := ContainerMetrics{
+		Time:             stats.Read,
+		MemoryLimitBytes: stats.MemoryStats.Limit,
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		metrics.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+	}
+
+	// page cache is reclaimable so it isn't counted, the key differs between cgroup v1 and v2
+	cache, ok := stats.MemoryStats.Stats["inactive_file"]
+	if !ok {
+		cache = stats.MemoryStats.Stats["total_inactive_file"]
+	}
+	metrics.MemoryBytes = stats.MemoryStats.Usage
+	if cache < metrics.MemoryBytes {
+		metrics.MemoryBytes -= cache
+	}
+	if metrics.MemoryLimitBytes > 0 {
+		metrics.MemoryPercent = float64(metrics.MemoryBytes) / float64(metrics.MemoryLimitBytes) * 100
+	}
+
+	for _, network := range stats.Networks {
+		metrics.NetRxBytes += network.RxBytes
+		metrics.NetTxBytes += network.TxBytes
+	}
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			metrics.BlockReadBytes += entry.Value
+		case "write":
+			metrics.BlockWriteBytes += entry.Value
+		}
+	}
+	return metrics
+}
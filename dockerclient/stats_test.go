@@ -0,0 +1,116 @@
+package dockerclient
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func cpuStats(total uint64, system uint64, onlineCPUs uint32, perCPU int) container.CPUStats {
+	return container.CPUStats{
+		CPUUsage:    container.CPUUsage{TotalUsage: total, PercpuUsage: make([]uint64, perCPU)},
+		SystemUsage: system,
+		OnlineCPUs:  onlineCPUs,
+	}
+}
+
+func TestMetricsFromStatsCPU(t *testing.T) {
+	tests := []struct {
+		desc     string
+		current  container.CPUStats
+		previous container.CPUStats
+		want     float64
+	}{
+		{"delta over online cpus", cpuStats(300, 2000, 2, 0), cpuStats(100, 1000, 2, 0), 40},
+		{"per-cpu count when online cpus is missing", cpuStats(300, 2000, 0, 4), cpuStats(100, 1000, 0, 4), 80},
+		{"first sample without a previous one", cpuStats(300, 2000, 2, 0), container.CPUStats{}, 30},
+		{"no cpu used", cpuStats(100, 2000, 2, 0), cpuStats(100, 1000, 2, 0), 0},
+		{"counter reset", cpuStats(50, 2000, 2, 0), cpuStats(100, 1000, 2, 0), 0},
+		{"no system time passed", cpuStats(300, 1000, 2, 0), cpuStats(100, 1000, 2, 0), 0},
+	}
+	for _, test := range tests {
+		metrics := metricsFromStats(container.StatsResponse{CPUStats: test.current, PreCPUStats: test.previous})
+		if math.Abs(metrics.CPUPercent-test.want) > 1e-9 {
+			t.Errorf("%s: CPUPercent = %v, want %v", test.desc, metrics.CPUPercent, test.want)
+		}
+	}
+}
+
+func TestMetricsFromStatsMemoryAndIO(t *testing.T) {
+	stats := container.StatsResponse{
+		MemoryStats: container.MemoryStats{
+			Usage: 600,
+			Limit: 1000,
+			Stats: map[string]uint64{"inactive_file": 100, "total_inactive_file": 300},
+		},
+		Networks: map[string]container.NetworkStats{
+			"eth0": {RxBytes: 10, TxBytes: 20},
+			"eth1": {RxBytes: 1, TxBytes: 2},
+		},
+		BlkioStats: container.BlkioStats{IoServiceBytesRecursive: []container.BlkioStatEntry{
+			{Op: "Read", Value: 5},
+			{Op: "read", Value: 5},
+			{Op: "Write", Value: 7},
+			{Op: "Total", Value: 17},
+		}},
+	}
+	metrics := metricsFromStats(stats)
+	if metrics.MemoryBytes != 500 || metrics.MemoryPercent != 50 {
+		t.Errorf("memory = %d bytes, %v%%, want the cgroup v2 cache left out", metrics.MemoryBytes, metrics.MemoryPercent)
+	}
+	if metrics.NetRxBytes != 11 || metrics.NetTxBytes != 22 {
+		t.Errorf("network = %d rx, %d tx, want the sum over interfaces", metrics.NetRxBytes, metrics.NetTxBytes)
+	}
+	if metrics.BlockReadBytes != 10 || metrics.BlockWriteBytes != 7 {
+		t.Errorf("block io = %d read, %d written", metrics.BlockReadBytes, metrics.BlockWriteBytes)
+	}
+
+	delete(stats.MemoryStats.Stats, "inactive_file")
+	if metrics := metricsFromStats(stats); metrics.MemoryBytes != 300 {
+		t.Errorf("MemoryBytes = %d, want the cgroup v1 cache left out", metrics.MemoryBytes)
+	}
+	stats.MemoryStats.Limit = 0
+	if metrics := metricsFromStats(stats); metrics.MemoryPercent != 0 {
+		t.Errorf("MemoryPercent = %v without a limit, want 0", metrics.MemoryPercent)
+	}
+}
+
+func TestStatsCollectorTrimsHistory(t *testing.T) {
+	collector := NewStatsCollector(3)
+	stats := &containerStats{live: true}
+	collector.containers["phaas-billing-api"] = stats
+	start := time.Now()
+	for i := range 5 {
+		collector.record("phaas-billing-api", stats, ContainerMetrics{Time: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	history := collector.History("phaas-billing-api")
+	if len(history) != 3 || !history[0].Time.Equal(start.Add(2*time.Second)) {
+		t.Errorf("History() = %v, want the last 3 samples", history)
+	}
+	current, ok := collector.Current("phaas-billing-api")
+	if !ok || !current.Time.Equal(start.Add(4*time.Second)) {
+		t.Errorf("Current() = %v, %t, want the latest sample", current, ok)
+	}
+}
+
+func TestStatsCollectorIgnoresReplacedStream(t *testing.T) {
+	collector := NewStatsCollector(3)
+	old := &containerStats{live: true}
+	replacement := &containerStats{live: true}
+	// the container was dropped from Sync and added back with a new stream before the old one ended
+	collector.containers["phaas-billing-api"] = replacement
+
+	collector.record("phaas-billing-api", old, ContainerMetrics{})
+	collector.streamEnded("phaas-billing-api", old)
+	if !replacement.live || len(replacement.history) != 0 {
+		t.Errorf("replacement = %+v, want it live and untouched by the old stream", replacement)
+	}
+
+	collector.streamEnded("phaas-billing-api", replacement)
+	if replacement.live {
+		t.Error("replacement is still live after its own stream ended")
+	}
+}
@@ -33,6 +33,7 @@ export class SettingsComponent implements OnInit {
       key: FormControl<string | null>,
       value: FormControl<string | null>,
       enabled: FormControl<boolean | null>,
+      secret: FormControl<boolean | null>,
     }>>([]),
   });
 
@@ -48,6 +49,7 @@ export class SettingsComponent implements OnInit {
               key: new FormControl(param.key),
               value: new FormControl(param.value),
               enabled: new FormControl(param.enabled),
+              secret: new FormControl(param.secret),
             }));
           });
         }
@@ -61,11 +63,12 @@ export class SettingsComponent implements OnInit {
       key: new FormControl(''),
       value: new FormControl(''),
       enabled: new FormControl(true),
+      secret: new FormControl(false),
     }));
   }
 
   save() {
-    SaveSettings(new app.Settings(this.form.value)).then(
+    SaveSettings(new app.SettingsUpdate(this.form.value)).then(
       () => console.log(`[Settings] Saved settings`),
       (err) => console.log(`[Settings] Failed to save settings`, err),
     );
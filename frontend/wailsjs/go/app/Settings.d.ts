@@ -1,12 +1,35 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {app} from '../models';
+import {dockerclient} from '../models';
 import {context} from '../models';
 
-export function GetEnvParamOverrides():Promise<Array<app.EnvParam>>;
+export function ExportEnvFile(arg1:string):Promise<void>;
+
+export function ExportSettingsBundle(arg1:string):Promise<void>;
+
+export function GetActiveEnvPreset():Promise<string>;
 
 export function GetSettings():Promise<app.Settings>;
 
-export function SaveSettings(arg1:app.Settings):Promise<void>;
+export function GetSettingsStatus():Promise<app.SettingsStatus>;
+
+export function ImportEnvFile(arg1:string):Promise<app.EnvImportReport>;
+
+export function ImportSettingsBundle(arg1:string):Promise<app.BundleImportReport>;
+
+export function ListDockerContexts():Promise<Array<dockerclient.DockerContext>>;
+
+export function ResolveEnv(arg1:string,arg2:Array<app.EnvParam>):Promise<Array<app.ResolvedEnvParam>>;
+
+export function ResolveEnvForPreset(arg1:string,arg2:string):Promise<Array<app.ResolvedEnvParam>>;
+
+export function SaveSettings(arg1:app.SettingsUpdate):Promise<void>;
+
+export function SetActiveEnvPreset(arg1:string):Promise<void>;
 
 export function Startup(arg1:context.Context):Promise<void>;
+
+export function TestDockerConnection(arg1:app.DockerSettings):Promise<dockerclient.ConnectionInfo>;
+
+export function Validate():Promise<Array<app.FieldError>>;
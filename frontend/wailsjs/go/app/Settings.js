@@ -2,18 +2,62 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function GetEnvParamOverrides() {
-  return window['go']['app']['Settings']['GetEnvParamOverrides']();
+export function ExportEnvFile(arg1) {
+  return window['go']['app']['Settings']['ExportEnvFile'](arg1);
+}
+
+export function ExportSettingsBundle(arg1) {
+  return window['go']['app']['Settings']['ExportSettingsBundle'](arg1);
+}
+
+export function GetActiveEnvPreset() {
+  return window['go']['app']['Settings']['GetActiveEnvPreset']();
 }
 
 export function GetSettings() {
   return window['go']['app']['Settings']['GetSettings']();
 }
 
+export function GetSettingsStatus() {
+  return window['go']['app']['Settings']['GetSettingsStatus']();
+}
+
+export function ImportEnvFile(arg1) {
+  return window['go']['app']['Settings']['ImportEnvFile'](arg1);
+}
+
+export function ImportSettingsBundle(arg1) {
+  return window['go']['app']['Settings']['ImportSettingsBundle'](arg1);
+}
+
+export function ListDockerContexts() {
+  return window['go']['app']['Settings']['ListDockerContexts']();
+}
+
+export function ResolveEnv(arg1, arg2) {
+  return window['go']['app']['Settings']['ResolveEnv'](arg1, arg2);
+}
+
+export function ResolveEnvForPreset(arg1, arg2) {
+  return window['go']['app']['Settings']['ResolveEnvForPreset'](arg1, arg2);
+}
+
 export function SaveSettings(arg1) {
   return window['go']['app']['Settings']['SaveSettings'](arg1);
 }
 
+export function SetActiveEnvPreset(arg1) {
+  return window['go']['app']['Settings']['SetActiveEnvPreset'](arg1);
+}
+
 export function Startup(arg1) {
   return window['go']['app']['Settings']['Startup'](arg1);
 }
+
+export function TestDockerConnection(arg1) {
+  return window['go']['app']['Settings']['TestDockerConnection'](arg1);
+}
+
+export function Validate() {
+  return window['go']['app']['Settings']['Validate']();
+}
@@ -0,0 +1,8 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {diagnostics} from '../models';
+import {context} from '../models';
+
+export function GetAppLogs(arg1:diagnostics.AppLogFilter):Promise<Array<diagnostics.AppLogEntry>>;
+
+export function Startup(arg1:context.Context):Promise<void>;
@@ -0,0 +1,11 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function GetAppLogs(arg1) {
+  return window['go']['diagnostics']['Diagnostics']['GetAppLogs'](arg1);
+}
+
+export function Startup(arg1) {
+  return window['go']['diagnostics']['Diagnostics']['Startup'](arg1);
+}
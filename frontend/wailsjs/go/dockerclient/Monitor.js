@@ -0,0 +1,7 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function IsDockerAvailable() {
+  return window['go']['dockerclient']['Monitor']['IsDockerAvailable']();
+}
@@ -1,45 +1,854 @@
 export namespace app {
 	
+	export enum RestartMode {
+	    never = "never",
+	    onFailure = "on-failure",
+	    always = "always",
+	}
+	export enum EnvLayer {
+	    global = "global",
+	    preset = "preset",
+	    envFile = "env-file",
+	    repo = "repo",
+	    oneOff = "one-off",
+	}
+	export class AppLogRetention {
+	    maxSizeMB: number;
+	    maxAgeDays: number;
+	    maxBackups: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AppLogRetention(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxSizeMB = source["maxSizeMB"];
+	        this.maxAgeDays = source["maxAgeDays"];
+	        this.maxBackups = source["maxBackups"];
+	    }
+	}
+	export class EnvImportConflict {
+	    key: string;
+	    previousValue: string;
+	    importedValue: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnvImportConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.previousValue = source["previousValue"];
+	        this.importedValue = source["importedValue"];
+	    }
+	}
+	export class EnvImportReport {
+	    added: string[];
+	    unchanged: string[];
+	    conflicts: EnvImportConflict[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EnvImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.added = source["added"];
+	        this.unchanged = source["unchanged"];
+	        this.conflicts = this.convertValues(source["conflicts"], EnvImportConflict);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BundleImportReport {
+	    envParams: EnvImportReport;
+	    envPresets: string[];
+	    repos: string[];
+	    missingSecrets: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BundleImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.envParams = this.convertValues(source["envParams"], EnvImportReport);
+	        this.envPresets = source["envPresets"];
+	        this.repos = source["repos"];
+	        this.missingSecrets = source["missingSecrets"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DockerSettings {
+	    host: string;
+	    context: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DockerSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.context = source["context"];
+	    }
+	}
+	
+	
 	export class EnvParam {
 	    key: string;
 	    value: string;
 	    enabled: boolean;
+	    secret: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnvParam(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.enabled = source["enabled"];
+	        this.secret = source["secret"];
+	    }
+	}
+	export class EnvPreset {
+	    name: string;
+	    envParams: EnvParam[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EnvPreset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.envParams = this.convertValues(source["envParams"], EnvParam);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FieldError {
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+	export class NotificationSettings {
+	    enabled: boolean;
+	    onReady: boolean;
+	    onStopped: boolean;
+	    onCrash: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NotificationSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.onReady = source["onReady"];
+	        this.onStopped = source["onStopped"];
+	        this.onCrash = source["onCrash"];
+	    }
+	}
+	export class RestartPolicy {
+	    mode: RestartMode;
+	    maxRetries: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestartPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.maxRetries = source["maxRetries"];
+	    }
+	}
+	export class RepoSettings {
+	    restartPolicy: RestartPolicy;
+	    muteNotifications: boolean;
+	    envParams: EnvParam[];
+	    envFilePath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RepoSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.restartPolicy = this.convertValues(source["restartPolicy"], RestartPolicy);
+	        this.muteNotifications = source["muteNotifications"];
+	        this.envParams = this.convertValues(source["envParams"], EnvParam);
+	        this.envFilePath = source["envFilePath"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ResolvedEnvParam {
+	    key: string;
+	    value: string;
+	    secret: boolean;
+	    layer: EnvLayer;
+	    overriddenLayers: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ResolvedEnvParam(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.secret = source["secret"];
+	        this.layer = source["layer"];
+	        this.overriddenLayers = source["overriddenLayers"];
+	    }
+	}
+	
+	export class ServiceLogRetention {
+	    maxRuns: number;
+	    maxTotalSizeMB: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ServiceLogRetention(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxRuns = source["maxRuns"];
+	        this.maxTotalSizeMB = source["maxTotalSizeMB"];
+	    }
+	}
+	export class Settings {
+	    schemaVersion: number;
+	    reposDirPath: string;
+	    dataDirPath: string;
+	    shellExecutablePath: string;
+	    shellInitFilePath: string;
+	    envParams: EnvParam[];
+	    envPresets: EnvPreset[];
+	    activeEnvPreset: string;
+	    repos: Record<string, RepoSettings>;
+	    notifications: NotificationSettings;
+	    serviceLogs: ServiceLogRetention;
+	    appLogs: AppLogRetention;
+	    logLevel: string;
+	    docker: DockerSettings;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.schemaVersion = source["schemaVersion"];
+	        this.reposDirPath = source["reposDirPath"];
+	        this.dataDirPath = source["dataDirPath"];
+	        this.shellExecutablePath = source["shellExecutablePath"];
+	        this.shellInitFilePath = source["shellInitFilePath"];
+	        this.envParams = this.convertValues(source["envParams"], EnvParam);
+	        this.envPresets = this.convertValues(source["envPresets"], EnvPreset);
+	        this.activeEnvPreset = source["activeEnvPreset"];
+	        this.repos = this.convertValues(source["repos"], RepoSettings, true);
+	        this.notifications = this.convertValues(source["notifications"], NotificationSettings);
+	        this.serviceLogs = this.convertValues(source["serviceLogs"], ServiceLogRetention);
+	        this.appLogs = this.convertValues(source["appLogs"], AppLogRetention);
+	        this.logLevel = source["logLevel"];
+	        this.docker = this.convertValues(source["docker"], DockerSettings);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SettingsStatus {
+	    degraded: boolean;
+	    problems: FieldError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SettingsStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.degraded = source["degraded"];
+	        this.problems = this.convertValues(source["problems"], FieldError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SettingsUpdate {
+	    reposDirPath?: string;
+	    dataDirPath?: string;
+	    shellExecutablePath?: string;
+	    shellInitFilePath?: string;
+	    envParams?: EnvParam[];
+	    envPresets?: EnvPreset[];
+	    activeEnvPreset?: string;
+	    repos?: Record<string, RepoSettings>;
+	    notifications?: NotificationSettings;
+	    serviceLogs?: ServiceLogRetention;
+	    appLogs?: AppLogRetention;
+	    logLevel?: string;
+	    docker?: DockerSettings;
+	
+	    static createFrom(source: any = {}) {
+	        return new SettingsUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.reposDirPath = source["reposDirPath"];
+	        this.dataDirPath = source["dataDirPath"];
+	        this.shellExecutablePath = source["shellExecutablePath"];
+	        this.shellInitFilePath = source["shellInitFilePath"];
+	        this.envParams = this.convertValues(source["envParams"], EnvParam);
+	        this.envPresets = this.convertValues(source["envPresets"], EnvPreset);
+	        this.activeEnvPreset = source["activeEnvPreset"];
+	        this.repos = this.convertValues(source["repos"], RepoSettings, true);
+	        this.notifications = this.convertValues(source["notifications"], NotificationSettings);
+	        this.serviceLogs = this.convertValues(source["serviceLogs"], ServiceLogRetention);
+	        this.appLogs = this.convertValues(source["appLogs"], AppLogRetention);
+	        this.logLevel = source["logLevel"];
+	        this.docker = this.convertValues(source["docker"], DockerSettings);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace diagnostics {
+	
+	export class AppLogEntry {
+	    // Go type: time
+	    time: any;
+	    level: string;
+	    msg: string;
+	    attrs: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new AppLogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.level = source["level"];
+	        this.msg = source["msg"];
+	        this.attrs = source["attrs"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AppLogFilter {
+	    minLevel: string;
+	    search: string;
+	    // Go type: time
+	    since: any;
+	    // Go type: time
+	    until: any;
+	    limit: number;
+	    includeRotated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AppLogFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.minLevel = source["minLevel"];
+	        this.search = source["search"];
+	        this.since = this.convertValues(source["since"], null);
+	        this.until = this.convertValues(source["until"], null);
+	        this.limit = source["limit"];
+	        this.includeRotated = source["includeRotated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace dockerclient {
+	
+	export class ConnectionInfo {
+	    host: string;
+	    serverVersion: string;
+	    apiVersion: string;
+	    os: string;
+	    arch: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.serverVersion = source["serverVersion"];
+	        this.apiVersion = source["apiVersion"];
+	        this.os = source["os"];
+	        this.arch = source["arch"];
+	    }
+	}
+	export class ContainerMetrics {
+	    // Go type: time
+	    time: any;
+	    cpuPercent: number;
+	    memoryBytes: number;
+	    memoryLimitBytes: number;
+	    memoryPercent: number;
+	    netRxBytes: number;
+	    netTxBytes: number;
+	    blockReadBytes: number;
+	    blockWriteBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContainerMetrics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.cpuPercent = source["cpuPercent"];
+	        this.memoryBytes = source["memoryBytes"];
+	        this.memoryLimitBytes = source["memoryLimitBytes"];
+	        this.memoryPercent = source["memoryPercent"];
+	        this.netRxBytes = source["netRxBytes"];
+	        this.netTxBytes = source["netTxBytes"];
+	        this.blockReadBytes = source["blockReadBytes"];
+	        this.blockWriteBytes = source["blockWriteBytes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DockerContext {
+	    name: string;
+	    host: string;
+	    current: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DockerContext(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.host = source["host"];
+	        this.current = source["current"];
+	    }
+	}
+	export class ExecResult {
+	    output: string;
+	    exitCode: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExecResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.output = source["output"];
+	        this.exitCode = source["exitCode"];
+	    }
+	}
+	export class ImageInfo {
+	    id: string;
+	    tags: string[];
+	    // Go type: time
+	    created: any;
+	    sizeBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImageInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.tags = source["tags"];
+	        this.created = this.convertValues(source["created"], null);
+	        this.sizeBytes = source["sizeBytes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PruneReport {
+	    imagesDeleted: number;
+	    spaceReclaimed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PruneReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.imagesDeleted = source["imagesDeleted"];
+	        this.spaceReclaimed = source["spaceReclaimed"];
+	    }
+	}
+
+}
+
+export namespace history {
+	
+	export enum EventType {
+	    start = "start",
+	    stop = "stop",
+	    transition = "transition",
+	    crash = "crash",
+	}
+	export enum Trigger {
+	    user = "user",
+	    autoRestart = "auto-restart",
+	    envPreset = "env-preset",
+	    snapshotRestore = "snapshot-restore",
+	    databaseRestore = "database-restore",
+	}
+	export class Entry {
+	    // Go type: time
+	    time: any;
+	    repo: string;
+	    event: EventType;
+	    trigger?: Trigger;
+	    from?: string;
+	    to?: string;
+	    readyAfterMs?: number;
+	    reason?: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.repo = source["repo"];
+	        this.event = source["event"];
+	        this.trigger = source["trigger"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.readyAfterMs = source["readyAfterMs"];
+	        this.reason = source["reason"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace logquery {
+	
+	export class Line {
+	    number: number;
+	    raw: string;
+	    structured: boolean;
+	    // Go type: time
+	    time: any;
+	    level: string;
+	    msg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Line(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.number = source["number"];
+	        this.raw = source["raw"];
+	        this.structured = source["structured"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.level = source["level"];
+	        this.msg = source["msg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Query {
+	    search: string;
+	    regex: boolean;
+	    caseSensitive: boolean;
+	    levels: string[];
+	    // Go type: time
+	    since: any;
+	    // Go type: time
+	    until: any;
+	    offset: number;
+	    limit: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new EnvParam(source);
+	        return new Query(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.key = source["key"];
-	        this.value = source["value"];
-	        this.enabled = source["enabled"];
+	        this.search = source["search"];
+	        this.regex = source["regex"];
+	        this.caseSensitive = source["caseSensitive"];
+	        this.levels = source["levels"];
+	        this.since = this.convertValues(source["since"], null);
+	        this.until = this.convertValues(source["until"], null);
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class Settings {
-	    reposDirPath: string;
-	    dataDirPath: string;
-	    goPath: string;
-	    goRoot: string;
-	    dockerPath: string;
-	    shellExecutablePath: string;
-	    shellInitFilePath: string;
-	    envParams: EnvParam[];
+	export class Result {
+	    lines: Line[];
+	    total: number;
+	    nextOffset: number;
+	    hasMore: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new Settings(source);
+	        return new Result(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.reposDirPath = source["reposDirPath"];
-	        this.dataDirPath = source["dataDirPath"];
-	        this.goPath = source["goPath"];
-	        this.goRoot = source["goRoot"];
-	        this.dockerPath = source["dockerPath"];
-	        this.shellExecutablePath = source["shellExecutablePath"];
-	        this.shellInitFilePath = source["shellInitFilePath"];
-	        this.envParams = this.convertValues(source["envParams"], EnvParam);
+	        this.lines = this.convertValues(source["lines"], Line);
+	        this.total = source["total"];
+	        this.nextOffset = source["nextOffset"];
+	        this.hasMore = source["hasMore"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -87,6 +896,134 @@ export namespace repo {
 	        this.statusNotificationChannel = source["statusNotificationChannel"];
 	    }
 	}
+	export class DatabaseInfo {
+	    container: string;
+	    host: string;
+	    port: string;
+	    user: string;
+	    password: string;
+	    database: string;
+	    connectionString: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DatabaseInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.container = source["container"];
+	        this.host = source["host"];
+	        this.port = source["port"];
+	        this.user = source["user"];
+	        this.password = source["password"];
+	        this.database = source["database"];
+	        this.connectionString = source["connectionString"];
+	    }
+	}
+	export class DumpFile {
+	    name: string;
+	    // Go type: time
+	    createdAt: any;
+	    sizeBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DumpFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.sizeBytes = source["sizeBytes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LogChunk {
+	    content: string;
+	    nextOffset: number;
+	    eof: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogChunk(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.content = source["content"];
+	        this.nextOffset = source["nextOffset"];
+	        this.eof = source["eof"];
+	    }
+	}
+	export class LogFile {
+	    name: string;
+	    // Go type: time
+	    startedAt: any;
+	    sizeBytes: number;
+	    current: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.sizeBytes = source["sizeBytes"];
+	        this.current = source["current"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class QueryResult {
+	    columns: string[];
+	    rows: string[][];
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueryResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.columns = source["columns"];
+	        this.rows = source["rows"];
+	        this.truncated = source["truncated"];
+	    }
+	}
 	export class Status {
 	    state: State;
 	
@@ -102,3 +1039,231 @@ export namespace repo {
 
 }
 
+export namespace repobrowser {
+	
+	export enum ContainerRole {
+	    none = "",
+	    api = "api",
+	    mysql = "mysql",
+	}
+	export enum ImageAction {
+	    pull = "pull",
+	    build = "build",
+	}
+	export enum OrphanKind {
+	    container = "container",
+	    volume = "volume",
+	}
+	export class ContainerPort {
+	    hostIp: string;
+	    hostPort: number;
+	    containerPort: number;
+	    protocol: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContainerPort(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostIp = source["hostIp"];
+	        this.hostPort = source["hostPort"];
+	        this.containerPort = source["containerPort"];
+	        this.protocol = source["protocol"];
+	    }
+	}
+	export class ContainerView {
+	    id: string;
+	    name: string;
+	    image: string;
+	    state: string;
+	    status: string;
+	    ports: ContainerPort[];
+	    repo: string;
+	    role: ContainerRole;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContainerView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.image = source["image"];
+	        this.state = source["state"];
+	        this.status = source["status"];
+	        this.ports = this.convertValues(source["ports"], ContainerPort);
+	        this.repo = source["repo"];
+	        this.role = source["role"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Orphan {
+	    kind: OrphanKind;
+	    id: string;
+	    name: string;
+	    reason: string;
+	    sizeBytes: number;
+	    running: boolean;
+	    removed: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Orphan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.reason = source["reason"];
+	        this.sizeBytes = source["sizeBytes"];
+	        this.running = source["running"];
+	        this.removed = source["removed"];
+	        this.error = source["error"];
+	    }
+	}
+	export class OrphanReport {
+	    dryRun: boolean;
+	    orphans: Orphan[];
+	    reclaimableBytes: number;
+	    reclaimedBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrphanReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dryRun = source["dryRun"];
+	        this.orphans = this.convertValues(source["orphans"], Orphan);
+	        this.reclaimableBytes = source["reclaimableBytes"];
+	        this.reclaimedBytes = source["reclaimedBytes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RepoMetrics {
+	    repo: string;
+	    api?: dockerclient.ContainerMetrics;
+	    mysql?: dockerclient.ContainerMetrics;
+	
+	    static createFrom(source: any = {}) {
+	        return new RepoMetrics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repo = source["repo"];
+	        this.api = this.convertValues(source["api"], dockerclient.ContainerMetrics);
+	        this.mysql = this.convertValues(source["mysql"], dockerclient.ContainerMetrics);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RepoMetricsHistory {
+	    repo: string;
+	    api: dockerclient.ContainerMetrics[];
+	    mysql: dockerclient.ContainerMetrics[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RepoMetricsHistory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repo = source["repo"];
+	        this.api = this.convertValues(source["api"], dockerclient.ContainerMetrics);
+	        this.mysql = this.convertValues(source["mysql"], dockerclient.ContainerMetrics);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TerminalSession {
+	    id: string;
+	    outputEvent: string;
+	    inputEvent: string;
+	    closedEvent: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TerminalSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.outputEvent = source["outputEvent"];
+	        this.inputEvent = source["inputEvent"];
+	        this.closedEvent = source["closedEvent"];
+	    }
+	}
+
+}
+
@@ -1,20 +1,95 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {repobrowser} from '../models';
 import {repo} from '../models';
+import {dockerclient} from '../models';
+import {app} from '../models';
+import {history} from '../models';
+import {logquery} from '../models';
 import {context} from '../models';
 
+export function CancelRepoExecs(arg1:string):Promise<number>;
+
+export function CleanupOrphans(arg1:boolean):Promise<repobrowser.OrphanReport>;
+
+export function CloseRepoTerminal(arg1:string):Promise<void>;
+
+export function CreateRepoSnapshot(arg1:string,arg2:string):Promise<repo.DumpFile>;
+
+export function DeleteRepoSnapshot(arg1:string,arg2:string):Promise<void>;
+
+export function DumpRepoDatabase(arg1:string,arg2:string):Promise<repo.DumpFile>;
+
+export function ExecInRepoContainer(arg1:string,arg2:Array<string>,arg3:number):Promise<dockerclient.ExecResult>;
+
+export function FindOrphans():Promise<repobrowser.OrphanReport>;
+
+export function GetRepoDatabaseInfo(arg1:string,arg2:boolean):Promise<repo.DatabaseInfo>;
+
+export function GetRepoEffectiveEnv(arg1:string):Promise<Array<app.ResolvedEnvParam>>;
+
+export function GetRepoHistory(arg1:string,arg2:number):Promise<Array<history.Entry>>;
+
+export function GetRepoImage(arg1:string):Promise<dockerclient.ImageInfo>;
+
+export function GetRepoMetrics(arg1:string):Promise<repobrowser.RepoMetrics>;
+
+export function GetRepoMetricsHistory(arg1:string):Promise<repobrowser.RepoMetricsHistory>;
+
 export function GetRepoRepoStatusNotificationChannel(arg1:string):Promise<string>;
 
 export function GetRepoStatus(arg1:string):Promise<repo.Status>;
 
+export function GetReposAffectedByEnvPreset(arg1:string):Promise<Array<string>>;
+
 export function InitRepos():Promise<void>;
 
+export function ListContainers():Promise<Array<repobrowser.ContainerView>>;
+
+export function ListRepoDatabaseDumps(arg1:string):Promise<Array<repo.DumpFile>>;
+
+export function ListRepoLogs(arg1:string):Promise<Array<repo.LogFile>>;
+
+export function ListRepoMetrics():Promise<Array<repobrowser.RepoMetrics>>;
+
+export function ListRepoSnapshots(arg1:string):Promise<Array<repo.DumpFile>>;
+
 export function ListRepos():Promise<Array<repo.BasicDetails>>;
 
+export function OpenRepoTerminal(arg1:string,arg2:Array<string>,arg3:number,arg4:number):Promise<repobrowser.TerminalSession>;
+
+export function PruneRepoImages():Promise<dockerclient.PruneReport>;
+
+export function PullRepoBaseImages(arg1:string):Promise<Array<string>>;
+
+export function QueryRepoDatabase(arg1:string,arg2:string):Promise<repo.QueryResult>;
+
+export function QueryRepoLogs(arg1:string,arg2:string,arg3:logquery.Query):Promise<logquery.Result>;
+
+export function ReadRepoLog(arg1:string,arg2:string,arg3:number,arg4:number):Promise<repo.LogChunk>;
+
+export function RebuildRepoImage(arg1:string):Promise<dockerclient.ImageInfo>;
+
 export function RegisterRepoStatusWatcher(arg1:string):Promise<void>;
 
+export function RemoveContainer(arg1:string):Promise<void>;
+
+export function ResizeRepoTerminal(arg1:string,arg2:number,arg3:number):Promise<void>;
+
+export function RestoreRepoDatabase(arg1:string,arg2:string):Promise<void>;
+
+export function RestoreRepoSnapshot(arg1:string,arg2:string):Promise<void>;
+
+export function StartContainer(arg1:string):Promise<void>;
+
 export function StartRepo(arg1:string):Promise<void>;
 
+export function StartRepoWithEnv(arg1:string,arg2:Array<app.EnvParam>):Promise<void>;
+
 export function Startup(arg1:context.Context):Promise<void>;
 
+export function StopContainer(arg1:string):Promise<void>;
+
 export function StopRepo(arg1:string):Promise<void>;
+
+export function SwitchEnvPreset(arg1:string,arg2:boolean):Promise<Array<string>>;
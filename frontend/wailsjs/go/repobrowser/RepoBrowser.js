@@ -2,6 +2,62 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CancelRepoExecs(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['CancelRepoExecs'](arg1);
+}
+
+export function CleanupOrphans(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['CleanupOrphans'](arg1);
+}
+
+export function CloseRepoTerminal(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['CloseRepoTerminal'](arg1);
+}
+
+export function CreateRepoSnapshot(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['CreateRepoSnapshot'](arg1, arg2);
+}
+
+export function DeleteRepoSnapshot(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['DeleteRepoSnapshot'](arg1, arg2);
+}
+
+export function DumpRepoDatabase(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['DumpRepoDatabase'](arg1, arg2);
+}
+
+export function ExecInRepoContainer(arg1, arg2, arg3) {
+  return window['go']['repobrowser']['RepoBrowser']['ExecInRepoContainer'](arg1, arg2, arg3);
+}
+
+export function FindOrphans() {
+  return window['go']['repobrowser']['RepoBrowser']['FindOrphans']();
+}
+
+export function GetRepoDatabaseInfo(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['GetRepoDatabaseInfo'](arg1, arg2);
+}
+
+export function GetRepoEffectiveEnv(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['GetRepoEffectiveEnv'](arg1);
+}
+
+export function GetRepoHistory(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['GetRepoHistory'](arg1, arg2);
+}
+
+export function GetRepoImage(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['GetRepoImage'](arg1);
+}
+
+export function GetRepoMetrics(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['GetRepoMetrics'](arg1);
+}
+
+export function GetRepoMetricsHistory(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['GetRepoMetricsHistory'](arg1);
+}
+
 export function GetRepoRepoStatusNotificationChannel(arg1) {
   return window['go']['repobrowser']['RepoBrowser']['GetRepoRepoStatusNotificationChannel'](arg1);
 }
@@ -10,26 +66,110 @@ export function GetRepoStatus(arg1) {
   return window['go']['repobrowser']['RepoBrowser']['GetRepoStatus'](arg1);
 }
 
+export function GetReposAffectedByEnvPreset(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['GetReposAffectedByEnvPreset'](arg1);
+}
+
 export function InitRepos() {
   return window['go']['repobrowser']['RepoBrowser']['InitRepos']();
 }
 
+export function ListContainers() {
+  return window['go']['repobrowser']['RepoBrowser']['ListContainers']();
+}
+
+export function ListRepoDatabaseDumps(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['ListRepoDatabaseDumps'](arg1);
+}
+
+export function ListRepoLogs(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['ListRepoLogs'](arg1);
+}
+
+export function ListRepoMetrics() {
+  return window['go']['repobrowser']['RepoBrowser']['ListRepoMetrics']();
+}
+
+export function ListRepoSnapshots(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['ListRepoSnapshots'](arg1);
+}
+
 export function ListRepos() {
   return window['go']['repobrowser']['RepoBrowser']['ListRepos']();
 }
 
+export function OpenRepoTerminal(arg1, arg2, arg3, arg4) {
+  return window['go']['repobrowser']['RepoBrowser']['OpenRepoTerminal'](arg1, arg2, arg3, arg4);
+}
+
+export function PruneRepoImages() {
+  return window['go']['repobrowser']['RepoBrowser']['PruneRepoImages']();
+}
+
+export function PullRepoBaseImages(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['PullRepoBaseImages'](arg1);
+}
+
+export function QueryRepoDatabase(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['QueryRepoDatabase'](arg1, arg2);
+}
+
+export function QueryRepoLogs(arg1, arg2, arg3) {
+  return window['go']['repobrowser']['RepoBrowser']['QueryRepoLogs'](arg1, arg2, arg3);
+}
+
+export function ReadRepoLog(arg1, arg2, arg3, arg4) {
+  return window['go']['repobrowser']['RepoBrowser']['ReadRepoLog'](arg1, arg2, arg3, arg4);
+}
+
+export function RebuildRepoImage(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['RebuildRepoImage'](arg1);
+}
+
 export function RegisterRepoStatusWatcher(arg1) {
   return window['go']['repobrowser']['RepoBrowser']['RegisterRepoStatusWatcher'](arg1);
 }
 
+export function RemoveContainer(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['RemoveContainer'](arg1);
+}
+
+export function ResizeRepoTerminal(arg1, arg2, arg3) {
+  return window['go']['repobrowser']['RepoBrowser']['ResizeRepoTerminal'](arg1, arg2, arg3);
+}
+
+export function RestoreRepoDatabase(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['RestoreRepoDatabase'](arg1, arg2);
+}
+
+export function RestoreRepoSnapshot(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['RestoreRepoSnapshot'](arg1, arg2);
+}
+
+export function StartContainer(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['StartContainer'](arg1);
+}
+
 export function StartRepo(arg1) {
   return window['go']['repobrowser']['RepoBrowser']['StartRepo'](arg1);
 }
 
+export function StartRepoWithEnv(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['StartRepoWithEnv'](arg1, arg2);
+}
+
 export function Startup(arg1) {
   return window['go']['repobrowser']['RepoBrowser']['Startup'](arg1);
 }
 
+export function StopContainer(arg1) {
+  return window['go']['repobrowser']['RepoBrowser']['StopContainer'](arg1);
+}
+
 export function StopRepo(arg1) {
   return window['go']['repobrowser']['RepoBrowser']['StopRepo'](arg1);
 }
+
+export function SwitchEnvPreset(arg1, arg2) {
+  return window['go']['repobrowser']['RepoBrowser']['SwitchEnvPreset'](arg1, arg2);
+}
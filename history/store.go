@@ -0,0 +1,169 @@
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"phaas-localservices-ui/app"
+	"slices"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventStart      EventType = "start"
+	EventStop       EventType = "stop"
+	EventTransition EventType = "transition"
+	EventCrash      EventType = "crash"
+)
+
+var AllEventTypes = []struct {
+	Value  EventType
+	TSName string
+}{
+	{EventStart, "start"},
+	{EventStop, "stop"},
+	{EventTransition, "transition"},
+	{EventCrash, "crash"},
+}
+
+// Trigger records what asked for a start or stop
+type Trigger string
+
+const (
+	TriggerUser            Trigger = "user"
+	TriggerAutoRestart     Trigger = "auto-restart"
+	TriggerEnvPreset       Trigger = "env-preset"
+	TriggerSnapshotRestore Trigger = "snapshot-restore"
+	TriggerDatabaseRestore Trigger = "database-restore"
+)
+
+var AllTriggers = []struct {
+	Value  Trigger
+	TSName string
+}{
+	{TriggerUser, "user"},
+	{TriggerAutoRestart, "autoRestart"},
+	{TriggerEnvPreset, "envPreset"},
+	{TriggerSnapshotRestore, "snapshotRestore"},
+	{TriggerDatabaseRestore, "databaseRestore"},
+}
+
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Repo    string    `json:"repo"`
+	Event   EventType `json:"event"`
+	Trigger Trigger   `json:"trigger,omitempty"`
+	From    string    `json:"from,omitempty"`
+	To      string    `json:"to,omitempty"`
+	// ReadyAfterMs is the time between the start request and the service reporting as running
+	ReadyAfterMs int64  `json:"readyAfterMs,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Store is an append-only, per-repo history of status transitions and start/stop calls, kept as JSON lines
+// under the settings directory
+type Store struct {
+	ctx   context.Context
+	dir   string
+	mutex sync.Mutex
+}
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+func (this *Store) Startup(ctx context.Context) error {
+	this.ctx = ctx
+	settingsDir, err := app.GetSettingsDir()
+	if err != nil {
+		return fmt.Errorf("could not get user settings dir: %w", err)
+	}
+	dir := filepath.Join(settingsDir, "history")
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(ctx, "Could not create history directory")
+		return fmt.Errorf("could not create history directory: %w", err)
+	}
+	this.mutex.Lock()
+	this.dir = dir
+	this.mutex.Unlock()
+	return nil
+}
+
+var ErrNotStarted = errors.New("history store not started")
+
+func (this *Store) Append(entry Entry) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.dir == "" {
+		return ErrNotStarted
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+	file, err := os.OpenFile(this.repoFile(entry.Repo), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+	return nil
+}
+
+// List returns up to limit of the most recent entries for the repo, newest first. A limit of zero or less returns
+// every entry.
+func (this *Store) List(repoName string, limit int) ([]Entry, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.dir == "" {
+		return nil, ErrNotStarted
+	}
+	file, err := os.Open(this.repoFile(repoName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Entry{}, nil
+		}
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			slog.With(slog.Any("error", err), slog.String("repo", repoName)).WarnContext(this.ctx, "Skipping malformed history entry")
+			continue
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) > limit {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+func (this *Store) repoFile(repoName string) string {
+	return filepath.Join(this.dir, filepath.Base(repoName)+".jsonl")
+}
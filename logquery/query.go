@@ -0,0 +1,246 @@
+package logquery
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLimit = 200
+	maxLimit     = 5000
+	maxLineBytes = 4 * 1024 * 1024
+)
+
+type Query struct {
+	// Search matches against the whole line, as a substring or as a regular expression when Regex is set
+	Search        string `json:"search"`
+	Regex         bool   `json:"regex"`
+	CaseSensitive bool   `json:"caseSensitive"`
+	// Levels keeps only JSON lines whose level is one of these, e.g. ["error", "warn"]. Lines without a level are
+	// dropped when it is set.
+	Levels []string `json:"levels"`
+	// Since and Until keep only JSON lines with a timestamp in range. Lines without a timestamp are dropped when
+	// either is set.
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// Offset skips that many matching lines, Limit caps the lines returned (default 200, max 5000)
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+type Line struct {
+	// Number is the 1-based line number in the file
+	Number int    `json:"number"`
+	Raw    string `json:"raw"`
+	// Structured is set when the line holds a JSON object, in which case Time, Level and Msg are filled from it
+	// where present
+	Structured bool      `json:"structured"`
+	Time       time.Time `json:"time"`
+	Level      string    `json:"level"`
+	Msg        string    `json:"msg"`
+}
+
+type Result struct {
+	Lines []Line `json:"lines"`
+	// Total counts every matching line in the file, not just the returned page
+	Total      int  `json:"total"`
+	NextOffset int  `json:"nextOffset"`
+	HasMore    bool `json:"hasMore"`
+}
+
+type matcher struct {
+	query  Query
+	regex  *regexp.Regexp
+	search string
+	levels []string
+}
+
+func newMatcher(query Query) (*matcher, error) {
+	m := &matcher{query: query}
+	if query.Search != "" {
+		if query.Regex {
+			pattern := query.Search
+			if !query.CaseSensitive {
+				pattern = "(?i)" + pattern
+			}
+			regex, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid search regex: %w", err)
+			}
+			m.regex = regex
+		} else if query.CaseSensitive {
+			m.search = query.Search
+		} else {
+			m.search = strings.ToLower(query.Search)
+		}
+	}
+	for _, level := range query.Levels {
+		m.levels = append(m.levels, normalizeLevel(level))
+	}
+	return m, nil
+}
+
+func (this *matcher) matches(line Line) bool {
+	switch {
+	case this.regex != nil:
+		if !this.regex.MatchString(line.Raw) {
+			return false
+		}
+	case this.search != "":
+		raw := line.Raw
+		if !this.query.CaseSensitive {
+			raw = strings.ToLower(raw)
+		}
+		if !strings.Contains(raw, this.search) {
+			return false
+		}
+	}
+	if len(this.levels) > 0 && !slices.Contains(this.levels, line.Level) {
+		return false
+	}
+	if !this.query.Since.IsZero() || !this.query.Until.IsZero() {
+		if line.Time.IsZero() {
+			return false
+		}
+		if !this.query.Since.IsZero() && line.Time.Before(this.query.Since) {
+			return false
+		}
+		if !this.query.Until.IsZero() && line.Time.After(this.query.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run scans the log and returns the page of lines matching the query
+func Run(log io.Reader, query Query) (Result, error) {
+	m, err := newMatcher(query)
+	if err != nil {
+		return Result{}, err
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	limit = min(limit, maxLimit)
+	offset := max(query.Offset, 0)
+
+	result := Result{Lines: []Line{}}
+	scanner := bufio.NewScanner(log)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := ParseLine(number, scanner.Text())
+		if !m.matches(line) {
+			continue
+		}
+		result.Total++
+		if result.Total <= offset || len(result.Lines) >= limit {
+			continue
+		}
+		result.Lines = append(result.Lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return Result{}, fmt.Errorf("failed to read log: %w", err)
+	}
+	result.NextOffset = offset + len(result.Lines)
+	result.HasMore = result.NextOffset < result.Total
+	return result, nil
+}
+
+var (
+	timeKeys  = []string{"time", "ts", "timestamp", "@timestamp"}
+	levelKeys = []string{"level", "lvl", "severity"}
+	msgKeys   = []string{"msg", "message"}
+)
+
+// ParseLine parses a log line, picking out the time, level and message of JSON lines. Output from docker compose
+// prefixes each line with the container name, so the JSON object may start part way through the line.
+func ParseLine(number int, raw string) Line {
+	line := Line{Number: number, Raw: raw}
+	start := strings.IndexByte(raw, '{')
+	if start < 0 {
+		return line
+	}
+	var fields map[string]any
+	if json.Unmarshal([]byte(raw[start:]), &fields) != nil {
+		return line
+	}
+	line.Structured = true
+	for _, key := range timeKeys {
+		if t, ok := parseTime(fields[key]); ok {
+			line.Time = t
+			break
+		}
+	}
+	for _, key := range levelKeys {
+		if level, ok := fields[key].(string); ok {
+			line.Level = normalizeLevel(level)
+			break
+		}
+	}
+	for _, key := range msgKeys {
+		if msg, ok := fields[key].(string); ok {
+			line.Msg = msg
+			break
+		}
+	}
+	return line
+}
+
+func parseTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	case float64:
+		return parseEpoch(v), true
+	}
+	return time.Time{}, false
+}
+
+// epochUnits tells epoch timestamps in seconds (zap), milliseconds (pino, logback, Date.now()), microseconds and
+// nanoseconds apart by magnitude. Anything from 1e11 up is past the year 5000 in seconds, so it must be a finer unit.
+var epochUnits = []struct {
+	threshold float64
+	unit      time.Duration
+}{
+	{1e17, time.Nanosecond},
+	{1e14, time.Microsecond},
+	{1e11, time.Millisecond},
+}
+
+func parseEpoch(value float64) time.Time {
+	unit := time.Second
+	for _, epochUnit := range epochUnits {
+		if math.Abs(value) >= epochUnit.threshold {
+			unit = epochUnit.unit
+			break
+		}
+	}
+	whole, fraction := math.Modf(value)
+	perSecond := int64(time.Second / unit)
+	nanos := (int64(whole)%perSecond)*int64(unit) + int64(fraction*float64(unit))
+	return time.Unix(int64(whole)/perSecond, nanos)
+}
+
+func normalizeLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	switch level {
+	case "warning":
+		return "warn"
+	case "err":
+		return "error"
+	case "critical", "panic":
+		return "fatal"
+	}
+	return level
+}
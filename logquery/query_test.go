@@ -0,0 +1,99 @@
+package logquery
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLineTimestamps(t *testing.T) {
+	want := time.Date(2024, 5, 1, 12, 30, 45, 250_000_000, time.UTC)
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"rfc3339", `{"time":"2024-05-01T12:30:45.25Z","msg":"hi"}`},
+		{"rfc3339 with offset", `{"ts":"2024-05-01T14:30:45.25+02:00"}`},
+		{"epoch seconds", `{"ts":1714566645.25}`},
+		{"epoch milliseconds", `{"timestamp":1714566645250}`},
+		{"epoch microseconds", `{"@timestamp":1714566645250000}`},
+		{"epoch nanoseconds", `{"time":1714566645250000000}`},
+		{"compose prefix", `api-1  | {"time":"2024-05-01T12:30:45.25Z"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line := ParseLine(1, test.raw)
+			if !line.Structured {
+				t.Fatal("expected a structured line")
+			}
+			// float64 can't hold nanosecond epochs exactly, so allow for rounding
+			if diff := line.Time.Sub(want).Abs(); diff > time.Microsecond {
+				t.Errorf("Time = %s, want %s", line.Time.UTC(), want)
+			}
+		})
+	}
+}
+
+func TestParseLineFields(t *testing.T) {
+	line := ParseLine(7, `{"severity":"WARNING","message":"disk almost full"}`)
+	if line.Number != 7 || line.Level != "warn" || line.Msg != "disk almost full" || !line.Time.IsZero() {
+		t.Errorf("unexpected line %+v", line)
+	}
+
+	plain := ParseLine(1, "starting server on :8080")
+	if plain.Structured || plain.Level != "" {
+		t.Errorf("plain text line parsed as structured: %+v", plain)
+	}
+}
+
+func TestRun(t *testing.T) {
+	log := strings.Join([]string{
+		`{"level":"info","time":1714566600000,"msg":"starting"}`,
+		`plain text ERROR line`,
+		`{"level":"error","time":1714566660000,"msg":"failed to connect"}`,
+		`{"level":"err","time":1714566720000,"msg":"failed again"}`,
+		`{"level":"info","time":1714566780000,"msg":"recovered"}`,
+	}, "\n")
+
+	tests := []struct {
+		name        string
+		query       Query
+		wantNumbers []int
+		wantTotal   int
+	}{
+		{"everything", Query{}, []int{1, 2, 3, 4, 5}, 5},
+		{"case insensitive search", Query{Search: "error"}, []int{2, 3}, 2},
+		{"case sensitive search", Query{Search: "ERROR", CaseSensitive: true}, []int{2}, 1},
+		{"regex", Query{Search: `failed (to|again)`, Regex: true}, []int{3, 4}, 2},
+		{"levels", Query{Levels: []string{"ERROR"}}, []int{3, 4}, 2},
+		{"since", Query{Since: time.UnixMilli(1714566700000)}, []int{4, 5}, 2},
+		{"until", Query{Until: time.UnixMilli(1714566660000)}, []int{1, 3}, 2},
+		{"paged", Query{Offset: 1, Limit: 2}, []int{2, 3}, 5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Run(strings.NewReader(log), test.query)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			numbers := make([]int, 0, len(result.Lines))
+			for _, line := range result.Lines {
+				numbers = append(numbers, line.Number)
+			}
+			if !slices.Equal(numbers, test.wantNumbers) || result.Total != test.wantTotal {
+				t.Errorf("Run() lines %v total %d, want %v total %d", numbers, result.Total, test.wantNumbers, test.wantTotal)
+			}
+			if result.HasMore != (result.NextOffset < result.Total) {
+				t.Errorf("HasMore = %t with NextOffset %d and Total %d", result.HasMore, result.NextOffset, result.Total)
+			}
+		})
+	}
+}
+
+func TestRunInvalidRegex(t *testing.T) {
+	_, err := Run(strings.NewReader(""), Query{Search: "(", Regex: true})
+	if err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
@@ -44,8 +44,19 @@ func Init(
 
 var ErrNotInitialized = errors.New("mage package not initialized")
 
-func Exec(ctx context.Context, path string, logTo io.Writer, commands ...string) (*os.Process, error) {
-	cmd, err := buildCmd(ctx, path, logTo, commands...)
+// Options describes where and how a mage command runs
+type Options struct {
+	// Dir is the repo directory to run mage in
+	Dir string
+	// RepoName selects the repo's env params when resolving the env for the command
+	RepoName string
+	// Env holds one-off env params layered over everything else for this command only
+	Env   []app.EnvParam
+	LogTo io.Writer
+}
+
+func Exec(ctx context.Context, opts Options, commands ...string) (*os.Process, error) {
+	cmd, err := buildCmd(ctx, opts, commands...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to build mage command: %w", err)
 	}
@@ -56,8 +67,8 @@ func Exec(ctx context.Context, path string, logTo io.Writer, commands ...string)
 	return cmd.Process, nil
 }
 
-func ExecWait(ctx context.Context, path string, logTo io.Writer, commands ...string) error {
-	cmd, err := buildCmd(ctx, path, logTo, commands...)
+func ExecWait(ctx context.Context, opts Options, commands ...string) error {
+	cmd, err := buildCmd(ctx, opts, commands...)
 	if err != nil {
 		return fmt.Errorf("unable to build mage command: %w", err)
 	}
@@ -65,24 +76,25 @@ func ExecWait(ctx context.Context, path string, logTo io.Writer, commands ...str
 	if err != nil {
 		return fmt.Errorf("failed to start mage command: %w", err)
 	}
-	return cmd.Run()
+	return cmd.Wait()
 }
 
-func buildCmd(ctx context.Context, path string, logTo io.Writer, commands ...string) (*exec.Cmd, error) {
+func buildCmd(ctx context.Context, opts Options, commands ...string) (*exec.Cmd, error) {
 	if defaultRunner == nil {
 		return nil, ErrNotInitialized
 	}
 	cmd := exec.CommandContext(ctx, defaultRunner.appSettings.ShellExecutablePath, "-c", strings.Join(append([]string{"mage"}, commands...), " "))
-	cmd.Dir = path
-	cmd.Stdout = logTo
-	cmd.Stderr = logTo
+	cmd.Dir = opts.Dir
+	cmd.Stdout = opts.LogTo
+	cmd.Stderr = opts.LogTo
 	cmd.Env = append(cmd.Environ(), "PHAAS_DOCKER_DISABLE_INTERACTIVE=1")
-	overrides := defaultRunner.appSettings.GetEnvParamOverrides()
-	envParams := make([]string, 0, len(overrides))
-	for _, param := range overrides {
-		if param.Enabled {
-			envParams = append(envParams, fmt.Sprintf("PHAAS_OVERRIDE_%s=%s", strings.ToUpper(param.Key), param.Value))
-		}
+	resolved, err := app.ResolveEnvWithSecrets(defaultRunner.appSettings, opts.RepoName, opts.Env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve env params: %w", err)
+	}
+	envParams := make([]string, 0, len(resolved))
+	for _, param := range resolved {
+		envParams = append(envParams, fmt.Sprintf("PHAAS_OVERRIDE_%s=%s", param.Key, param.Value))
 	}
 	cmd.Env = append(cmd.Env, envParams...)
 	return cmd, nil
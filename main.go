@@ -7,9 +7,11 @@ import (
 	"io"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/diagnostics"
+	"phaas-localservices-ui/history"
 	"phaas-localservices-ui/repo"
+	repobrowser "phaas-localservices-ui/repo_browser"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/logger"
@@ -20,27 +22,27 @@ import (
 //go:embed all:frontend/dist
 var assets embed.FS
 
-func SetupLogger() logger.Logger {
+func SetupLogger() (SlogLogger, *diagnostics.RotatingWriter) {
 	dir, err := app.GetSettingsDir()
 	if err != nil {
 		panic(fmt.Errorf("could not get user config dir: %w", err))
 	}
-	err = os.Mkdir(dir, os.ModeDir)
+	err = os.MkdirAll(dir, os.ModePerm)
 	if err != nil && !errors.Is(err, os.ErrExist) {
 		panic(fmt.Errorf("could not create user config dir: %w", err))
 	}
-	logFile, err := os.Create(filepath.Join(dir, "logs.json"))
+	logWriter, err := diagnostics.NewRotatingWriter(dir)
 	if err != nil {
 		panic(fmt.Errorf("could not create log file: %w", err))
 	}
 
-	multiwriter := io.MultiWriter(os.Stdout, logFile)
-	fileLogger := slog.New(slog.NewJSONHandler(multiwriter, &slog.HandlerOptions{}))
+	multiwriter := io.MultiWriter(os.Stdout, logWriter)
+	fileLogger := slog.New(slog.NewJSONHandler(multiwriter, &slog.HandlerOptions{Level: diagnostics.LogLevel}))
 	slog.SetDefault(fileLogger)
 	slogLogger := SlogLogger{
 		logger: fileLogger,
 	}
-	return slogLogger
+	return slogLogger, logWriter
 }
 
 type SlogLogger struct {
@@ -82,8 +84,10 @@ func (l SlogLogger) Trace(message string) {
 }
 
 func main() {
+	appLogger, logWriter := SetupLogger()
+
 	// Create an instance of the app structure
-	a := NewApp()
+	a := NewApp(logWriter)
 
 	// Create application with options
 	err := wails.Run(&options.App{
@@ -93,13 +97,22 @@ func main() {
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
-		Logger:           SetupLogger(),
-		LogLevel:         logger.INFO,
+		Logger: appLogger,
+		// filtering is left to the slog handler so the level can follow the settings
+		LogLevel:         logger.DEBUG,
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        a.startup,
+		OnShutdown:       a.shutdown,
 		Bind:             a.getExposedInterfaces(),
 		EnumBind: []interface{}{
 			repo.AllStates,
+			app.AllRestartModes,
+			app.AllEnvLayers,
+			history.AllEventTypes,
+			history.AllTriggers,
+			repobrowser.AllOrphanKinds,
+			repobrowser.AllContainerRoles,
+			repobrowser.AllImageActions,
 		},
 	})
 
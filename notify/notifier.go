@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/repo"
+	"time"
+)
+
+// sendTimeout bounds how long a single notification may take to deliver so a hung notification service can't pile up
+// goroutines
+const sendTimeout = 10 * time.Second
+
+type Notification struct {
+	Title string
+	Body  string
+}
+
+// Sink delivers notifications to the user
+type Sink interface {
+	Send(ctx context.Context, notification Notification) error
+}
+
+// Notifier turns repo status transitions into desktop notifications according to the notification settings
+type Notifier struct {
+	ctx      context.Context
+	settings *app.Settings
+	sink     Sink
+}
+
+func NewNotifier(appSettings *app.Settings, sink Sink) *Notifier {
+	return &Notifier{
+		ctx:      context.Background(),
+		settings: appSettings,
+		sink:     sink,
+	}
+}
+
+func (this *Notifier) Startup(ctx context.Context) {
+	this.ctx = ctx
+}
+
+// HandleTransition is called from the repo status watchers, so delivery happens in the background to keep a slow
+// notification service from holding up status refreshes
+func (this *Notifier) HandleTransition(transition repo.Transition) {
+	notification, ok := this.buildNotification(transition)
+	if !ok {
+		return
+	}
+	go this.send(transition.Repo, notification)
+}
+
+func (this *Notifier) send(repoName string, notification Notification) {
+	ctx, cancel := context.WithTimeout(this.ctx, sendTimeout)
+	defer cancel()
+	err := this.sink.Send(ctx, notification)
+	if err != nil {
+		slog.With(slog.Any("error", err), slog.String("repo", repoName)).ErrorContext(ctx, "Failed to send notification")
+	}
+}
+
+func (this *Notifier) buildNotification(transition repo.Transition) (Notification, bool) {
+	settings := app.NotificationSettingsOf(this.settings)
+	if !settings.Enabled || app.RepoSettingsOf(this.settings, transition.Repo).MuteNotifications {
+		return Notification{}, false
+	}
+
+	switch {
+	case transition.To == repo.StateRunning && settings.OnReady:
+		return Notification{
+			Title: fmt.Sprintf("%s is running", transition.Repo),
+			Body:  "The service finished starting and is ready.",
+		}, true
+	case transition.To == repo.StateStopped && transition.Unexpected && settings.OnCrash:
+		if transition.From == repo.StateRunning {
+			return Notification{
+				Title: fmt.Sprintf("%s crashed", transition.Repo),
+				Body:  "The service stopped unexpectedly. Check its service log for details.",
+			}, true
+		}
+		return Notification{
+			Title: fmt.Sprintf("%s failed to start", transition.Repo),
+			Body:  "The service stopped before it was ready. Check its service log for details.",
+		}, true
+	case transition.To == repo.StateStopped && !transition.Unexpected && settings.OnStopped:
+		return Notification{
+			Title: fmt.Sprintf("%s stopped", transition.Repo),
+			Body:  "The service was stopped.",
+		}, true
+	}
+	return Notification{}, false
+}
@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"context"
+	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/repo"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink keeps every notification it is sent instead of delivering it
+type recordingSink struct {
+	mutex         sync.Mutex
+	notifications []Notification
+	sent          chan struct{}
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{sent: make(chan struct{}, 16)}
+}
+
+func (this *recordingSink) Send(_ context.Context, notification Notification) error {
+	this.mutex.Lock()
+	this.notifications = append(this.notifications, notification)
+	this.mutex.Unlock()
+	this.sent <- struct{}{}
+	return nil
+}
+
+func (this *recordingSink) Notifications() []Notification {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return append([]Notification(nil), this.notifications...)
+}
+
+func allNotificationsEnabled() *app.Settings {
+	settings := app.NewSettings()
+	settings.Notifications = app.NotificationSettings{Enabled: true, OnReady: true, OnStopped: true, OnCrash: true}
+	return settings
+}
+
+func TestBuildNotification(t *testing.T) {
+	tests := []struct {
+		name       string
+		transition repo.Transition
+		wantTitle  string
+	}{
+		{
+			name:       "ready",
+			transition: repo.Transition{Repo: "phaas-a-api", From: repo.StateStarting, To: repo.StateRunning},
+			wantTitle:  "phaas-a-api is running",
+		},
+		{
+			name:       "crash",
+			transition: repo.Transition{Repo: "phaas-a-api", From: repo.StateRunning, To: repo.StateStopped, Unexpected: true},
+			wantTitle:  "phaas-a-api crashed",
+		},
+		{
+			name:       "failed start",
+			transition: repo.Transition{Repo: "phaas-a-api", From: repo.StateStarting, To: repo.StateStopped, Unexpected: true},
+			wantTitle:  "phaas-a-api failed to start",
+		},
+		{
+			name:       "requested stop",
+			transition: repo.Transition{Repo: "phaas-a-api", From: repo.StateRunning, To: repo.StateStopped},
+			wantTitle:  "phaas-a-api stopped",
+		},
+		{
+			name:       "starting is not notified",
+			transition: repo.Transition{Repo: "phaas-a-api", From: repo.StateStopped, To: repo.StateStarting},
+		},
+	}
+	notifier := NewNotifier(allNotificationsEnabled(), newRecordingSink())
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notification, ok := notifier.buildNotification(test.transition)
+			if ok != (test.wantTitle != "") {
+				t.Fatalf("buildNotification() ok = %t, want %t", ok, test.wantTitle != "")
+			}
+			if notification.Title != test.wantTitle {
+				t.Errorf("buildNotification() title = %q, want %q", notification.Title, test.wantTitle)
+			}
+		})
+	}
+}
+
+func TestBuildNotificationRespectsSettings(t *testing.T) {
+	crash := repo.Transition{Repo: "phaas-a-api", From: repo.StateRunning, To: repo.StateStopped, Unexpected: true}
+
+	disabled := allNotificationsEnabled()
+	disabled.Notifications.Enabled = false
+	if _, ok := NewNotifier(disabled, newRecordingSink()).buildNotification(crash); ok {
+		t.Error("expected no notification when notifications are disabled")
+	}
+
+	noCrash := allNotificationsEnabled()
+	noCrash.Notifications.OnCrash = false
+	if _, ok := NewNotifier(noCrash, newRecordingSink()).buildNotification(crash); ok {
+		t.Error("expected no notification when crash notifications are off")
+	}
+
+	muted := allNotificationsEnabled()
+	muted.Repos = map[string]app.RepoSettings{"phaas-a-api": {MuteNotifications: true}}
+	if _, ok := NewNotifier(muted, newRecordingSink()).buildNotification(crash); ok {
+		t.Error("expected no notification for a muted repo")
+	}
+}
+
+func TestHandleTransitionSendsToSink(t *testing.T) {
+	sink := newRecordingSink()
+	notifier := NewNotifier(allNotificationsEnabled(), sink)
+	notifier.HandleTransition(repo.Transition{Repo: "phaas-a-api", From: repo.StateStarting, To: repo.StateRunning})
+
+	select {
+	case <-sink.sent:
+	case <-time.After(time.Second):
+		t.Fatal("notification was not sent")
+	}
+	notifications := sink.Notifications()
+	if len(notifications) != 1 || notifications[0].Title != "phaas-a-api is running" {
+		t.Errorf("unexpected notifications %+v", notifications)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build darwin
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// desktopSink sends notifications through Notification Center using osascript
+type desktopSink struct{}
+
+func NewDesktopSink() Sink {
+	return desktopSink{}
+}
+
+func (this desktopSink) Send(ctx context.Context, notification Notification) error {
+	script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(notification.Body), strconv.Quote(notification.Title))
+	out, err := exec.CommandContext(ctx, "osascript", "-e", script).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to send notification with osascript: %w: %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const appName = "PHaaS Local Services"
+
+// desktopSink sends notifications through the freedesktop notification service on the session bus
+type desktopSink struct {
+	mutex sync.Mutex
+	conn  *dbus.Conn
+}
+
+func NewDesktopSink() Sink {
+	return &desktopSink{}
+}
+
+func (this *desktopSink) Send(ctx context.Context, notification Notification) error {
+	conn, err := this.connection()
+	if err != nil {
+		return err
+	}
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.CallWithContext(ctx, "org.freedesktop.Notifications.Notify", 0,
+		appName,
+		uint32(0),
+		"",
+		notification.Title,
+		notification.Body,
+		[]string{},
+		map[string]dbus.Variant{},
+		int32(-1),
+	)
+	if call.Err != nil {
+		return fmt.Errorf("failed to send notification over dbus: %w", call.Err)
+	}
+	return nil
+}
+
+func (this *desktopSink) connection() (*dbus.Conn, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.conn != nil && this.conn.Connected() {
+		return this.conn, nil
+	}
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to dbus session bus: %w", err)
+	}
+	this.conn = conn
+	return conn, nil
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin
+
+package notify
+
+import (
+	"context"
+	"log/slog"
+)
+
+// desktopSink logs notifications on platforms without a supported notification mechanism
+type desktopSink struct{}
+
+func NewDesktopSink() Sink {
+	return desktopSink{}
+}
+
+func (this desktopSink) Send(ctx context.Context, notification Notification) error {
+	slog.With(slog.String("title", notification.Title), slog.String("body", notification.Body)).InfoContext(ctx, "Notification")
+	return nil
+}
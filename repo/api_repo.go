@@ -5,12 +5,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"phaas-localservices-ui/app"
 	"phaas-localservices-ui/dockerclient"
+	"phaas-localservices-ui/history"
 	"phaas-localservices-ui/mage"
 	"phaas-localservices-ui/scheduler"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5"
@@ -27,9 +30,34 @@ type apiController struct {
 
 	jobScheduler *scheduler.Scheduler
 	appSettings  *app.Settings
+	history      *history.Store
+	docker       *dockerclient.Monitor
+	transitions  TransitionListener
 
-	startedPID   int
+	// pidMutex guards startedPID, which Start and Stop write while status refreshes read it
+	pidMutex   sync.Mutex
+	startedPID int
+
+	// statusMutex serializes status refreshes, which run from both status watcher jobs, from Start and from docker
+	// availability changes
+	statusMutex  sync.Mutex
 	latestStatus Status
+	// lastKnownStatus is the status from before docker became unavailable, which the first refresh after it is back
+	// reconciles against
+	lastKnownStatus  Status
+	runningSince     time.Time
+	startRequestedAt time.Time
+	knownPorts       map[string][]uint16
+
+	restartMutex    sync.Mutex
+	stopRequested   bool
+	restartAttempts int
+	pendingRestart  *time.Timer
+	// restartGeneration is bumped whenever a restart is scheduled or cancelled, so a timer that fired after being
+	// replaced, or a failed restart overtaken by Start or Stop, does nothing
+	restartGeneration int
+	// lastStartOptions are reused by automatic restarts so one-off env params survive a crash
+	lastStartOptions StartOptions
 }
 
 func (this *apiController) GetBasicDetails() BasicDetails {
@@ -66,21 +94,33 @@ func (this *apiController) GetActiveBranch() (string, error) {
 	return branch, nil
 }
 
-func (this *apiController) GetStatus() (Status, error) {
-
-	foundProcess := false
-	if this.startedPID != 0 {
-		proc, err := os.FindProcess(this.startedPID)
-		if err != nil {
-			this.startedPID = 0
-			if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, os.ErrProcessDone) {
-				slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to find started process")
-				// not returning so we can fall back to docker lookup
-			}
-		} else if proc != nil {
-			foundProcess = true
+// hasStartedProcess tells whether the mage process of the last start is still around
+func (this *apiController) hasStartedProcess() bool {
+	this.pidMutex.Lock()
+	defer this.pidMutex.Unlock()
+	if this.startedPID == 0 {
+		return false
+	}
+	proc, err := os.FindProcess(this.startedPID)
+	if err != nil {
+		this.startedPID = 0
+		if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, os.ErrProcessDone) {
+			slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to find started process")
+			// not returning an error so we can fall back to docker lookup
 		}
+		return false
 	}
+	return proc != nil
+}
+
+func (this *apiController) setStartedPID(pid int) {
+	this.pidMutex.Lock()
+	defer this.pidMutex.Unlock()
+	this.startedPID = pid
+}
+
+func (this *apiController) GetStatus() (Status, error) {
+	foundProcess := this.hasStartedProcess()
 
 	status, err := dockerclient.GetStatus(this.ctx, this.name)
 	if err != nil {
@@ -104,9 +144,28 @@ func (this *apiController) GetStatusNotificationChannel() string {
 	return fmt.Sprintf("events-%s-status", this.name)
 }
 
+func (this *apiController) statusWatcherJobName() string {
+	return fmt.Sprintf("%s-status-watcher", this.name)
+}
+
+func (this *apiController) lowLatencyStatusWatcherJobName() string {
+	return fmt.Sprintf("%s-status-watcher-low-latency", this.name)
+}
+
+func (this *apiController) Close() {
+	this.jobScheduler.RemoveJob(this.statusWatcherJobName())
+	this.jobScheduler.RemoveJob(this.lowLatencyStatusWatcherJobName())
+	this.restartMutex.Lock()
+	this.cancelPendingRestart()
+	this.restartMutex.Unlock()
+}
+
 func (this *apiController) RegisterStatusWatcher() error {
-	jobName := fmt.Sprintf("%s-status-watcher", this.name)
+	jobName := this.statusWatcherJobName()
 	err := this.jobScheduler.AddJob(jobName, 30*time.Second, func() {
+		if !this.docker.IsDockerAvailable() {
+			return
+		}
 		err := this.refreshStatus()
 		if err != nil {
 			slog.With(slog.Any("error", err), slog.String("repo", this.name)).ErrorContext(this.ctx, "Error refreshing status for repo")
@@ -120,13 +179,16 @@ func (this *apiController) RegisterStatusWatcher() error {
 }
 
 func (this *apiController) startLowLatencyStatusWatcher() {
-	jobName := fmt.Sprintf("%s-status-watcher-low-latency", this.name)
+	jobName := this.lowLatencyStatusWatcherJobName()
 	err := this.jobScheduler.AddJob(jobName, 1*time.Second, func() {
+		if !this.docker.IsDockerAvailable() {
+			return
+		}
 		err := this.refreshStatus()
 		if err != nil {
 			slog.With(slog.Any("error", err), slog.String("repo", this.name)).ErrorContext(this.ctx, "Error refreshing status for repo")
 		}
-		if this.latestStatus.State == StateStopped {
+		if this.currentStatus().State == StateStopped {
 			slog.InfoContext(this.ctx, "Stopping low-latency status watcher")
 			this.jobScheduler.RemoveJob(jobName)
 		}
@@ -137,7 +199,15 @@ func (this *apiController) startLowLatencyStatusWatcher() {
 	slog.InfoContext(this.ctx, "Started low-latency status watcher")
 }
 
+func (this *apiController) currentStatus() Status {
+	this.statusMutex.Lock()
+	defer this.statusMutex.Unlock()
+	return this.latestStatus
+}
+
 func (this *apiController) refreshStatus() error {
+	this.statusMutex.Lock()
+	defer this.statusMutex.Unlock()
 
 	newStatus, err := this.GetStatus()
 	if err != nil {
@@ -145,23 +215,114 @@ func (this *apiController) refreshStatus() error {
 		return fmt.Errorf("error getting status for repo: %w", err)
 	}
 
-	statusChanged := false
 	if this.latestStatus != newStatus {
-		statusChanged = true
+		runtime.EventsEmit(this.ctx, this.GetStatusNotificationChannel(), newStatus)
 	}
+	previousStatus := reconciledPrevious(this.latestStatus, this.lastKnownStatus)
 	this.latestStatus = newStatus
-	if statusChanged {
-		runtime.EventsEmit(this.ctx, this.GetStatusNotificationChannel(), this.latestStatus)
+	this.lastKnownStatus = Status{}
+	if previousStatus != newStatus {
+		entry := history.Entry{
+			Event: history.EventTransition,
+			From:  string(previousStatus.State),
+			To:    string(newStatus.State),
+		}
+		if newStatus.State == StateRunning {
+			this.rememberPorts()
+			this.runningSince = time.Now()
+			if !this.startRequestedAt.IsZero() {
+				entry.ReadyAfterMs = time.Since(this.startRequestedAt).Milliseconds()
+				this.startRequestedAt = time.Time{}
+			}
+		}
+		unexpected := false
+		if newStatus.State == StateStopped {
+			this.restartMutex.Lock()
+			unexpected = !this.stopRequested
+			this.restartMutex.Unlock()
+			if unexpected {
+				entry.Reason = "stopped unexpectedly"
+			} else {
+				entry.Reason = "stop requested"
+			}
+		}
+		// the first refresh after launch, or after docker comes back when nothing was known before, only establishes
+		// the current state, it isn't a transition
+		if previousStatus.State != "" && previousStatus.State != StateUnknown {
+			this.recordHistory(entry)
+			this.transitions(Transition{
+				Repo:       this.name,
+				From:       previousStatus.State,
+				To:         newStatus.State,
+				Unexpected: unexpected,
+			})
+		}
+		if previousStatus.State == StateRunning && newStatus.State == StateStopped {
+			this.handleStopped()
+		}
 	}
 	return nil
 }
 
-func (this *apiController) Start() error {
-	slog.With(slog.String("PATH", os.Getenv("PATH"))).InfoContext(this.ctx, "Starting")
+// reconciledPrevious is the status a refresh compares against. While docker was unavailable the status was unknown,
+// so the status from before is used instead, otherwise a service that crashed during the outage would go unnoticed.
+func reconciledPrevious(latest Status, lastKnown Status) Status {
+	if latest.State == StateUnknown && lastKnown.State != "" {
+		return lastKnown
+	}
+	return latest
+}
 
-	err := this.mysqlUp()
+func (this *apiController) DockerAvailabilityChanged(available bool) {
+	if available {
+		err := this.refreshStatus()
+		if err != nil {
+			slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Error reconciling status for repo")
+		}
+		return
+	}
+	this.statusMutex.Lock()
+	defer this.statusMutex.Unlock()
+	if this.latestStatus.State != StateUnknown {
+		this.lastKnownStatus = this.latestStatus
+	}
+	this.latestStatus = Status{State: StateUnknown}
+	runtime.EventsEmit(this.ctx, this.GetStatusNotificationChannel(), this.latestStatus)
+}
+
+func (this *apiController) recordHistory(entry history.Entry) {
+	entry.Repo = this.name
+	err := this.history.Append(entry)
 	if err != nil {
-		return err
+		slog.With(slog.Any("error", err), slog.String("event", string(entry.Event))).ErrorContext(this.ctx, "Failed to record history")
+	}
+}
+
+func (this *apiController) Start(opts StartOptions) error {
+	this.restartMutex.Lock()
+	this.cancelPendingRestart()
+	this.stopRequested = false
+	this.restartAttempts = 0
+	this.lastStartOptions = opts
+	this.restartMutex.Unlock()
+
+	return this.start(opts)
+}
+
+func (this *apiController) start(opts StartOptions) error {
+	entry := history.Entry{Event: history.EventStart, Trigger: opts.Trigger}
+	err := this.startService(opts)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	this.recordHistory(entry)
+	return err
+}
+
+func (this *apiController) startService(opts StartOptions) error {
+	slog.With(slog.String("PATH", os.Getenv("PATH"))).InfoContext(this.ctx, "Starting")
+	if !this.docker.IsDockerAvailable() {
+		return dockerclient.ErrDockerUnavailable
 	}
 
 	status, err := dockerclient.GetStatus(this.ctx, this.name)
@@ -173,29 +334,51 @@ func (this *apiController) Start() error {
 		slog.With(slog.String("repo", this.name)).InfoContext(this.ctx, "Already running")
 		return nil
 	}
+	// checked before mysql is brought up so a conflict doesn't leave mysql running for a service that can't start
+	mysqlRunning, err := this.isMysqlRunning()
+	if err != nil {
+		return err
+	}
+	err = this.checkPorts(mysqlRunning)
+	if err != nil {
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Refusing to start, ports in use")
+		return fmt.Errorf("cannot start repo: %w", err)
+	}
 
-	repoDataPath := fmt.Sprintf("%s/%s", this.appSettings.DataDirPath, this.name)
-	err = os.Mkdir(repoDataPath, os.ModePerm)
+	if !mysqlRunning {
+		err = this.mysqlUp(opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.Mkdir(this.repoDataPath(), os.ModePerm)
 	if err != nil && !errors.Is(err, os.ErrExist) {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Error creating repo data directory")
 		return fmt.Errorf("failed to create data dir: %w", err)
 	}
-	logFilePath := fmt.Sprintf("%s/service.log", repoDataPath)
-	logFile, err := os.Create(logFilePath)
+	logFile, err := this.createRunLog()
 	if err != nil {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Error opening repo log file")
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
-	proc, err := mage.Exec(this.ctx, this.path, logFile, "run")
+	// the started process gets its own copy of the file descriptor, so ours is closed as soon as it has started
+	defer logFile.Close()
+	proc, err := mage.Exec(this.ctx, this.mageOptions(opts, logFile), "run")
 	if err != nil {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Error executing mage run")
 		return fmt.Errorf("failed to start repo: %w", err)
 	}
 
+	pid := 0
 	if proc != nil {
-		this.startedPID = proc.Pid
+		pid = proc.Pid
+		this.setStartedPID(pid)
 	}
-	slog.With(slog.Int("pid", this.startedPID)).InfoContext(this.ctx, "Starting repo")
+	this.statusMutex.Lock()
+	this.startRequestedAt = time.Now()
+	this.statusMutex.Unlock()
+	slog.With(slog.Int("pid", pid)).InfoContext(this.ctx, "Starting repo")
 	err = this.refreshStatus()
 	if err != nil {
 		slog.With(slog.String("repo", this.name)).ErrorContext(this.ctx, "Error refreshing status for repo")
@@ -205,7 +388,52 @@ func (this *apiController) Start() error {
 	return nil
 }
 
-func (this *apiController) Stop() error {
+func (this *apiController) Stop(opts StopOptions) error {
+	this.restartMutex.Lock()
+	this.cancelPendingRestart()
+	this.stopRequested = true
+	this.restartMutex.Unlock()
+
+	entry := history.Entry{Event: history.EventStop, Trigger: opts.Trigger}
+	err := this.stopService()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	this.recordHistory(entry)
+	return err
+}
+
+const stopPollInterval = 500 * time.Millisecond
+
+var ErrStopTimeout = errors.New("timed out waiting for the service to stop")
+
+// WaitUntilStopped refreshes the status until the service reports as stopped. Going through refreshStatus means the
+// transition is handled while the stop is still marked as requested, rather than by a later watcher run that could
+// mistake it for a crash once a following Start has cleared the flag.
+func (this *apiController) WaitUntilStopped(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := this.refreshStatus()
+		if err != nil {
+			return err
+		}
+		if this.currentStatus().State == StateStopped {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrStopTimeout
+		}
+		time.Sleep(stopPollInterval)
+	}
+}
+
+func (this *apiController) LastStartOptions() StartOptions {
+	this.restartMutex.Lock()
+	defer this.restartMutex.Unlock()
+	return this.lastStartOptions
+}
+
+func (this *apiController) stopService() error {
 	status, err := dockerclient.GetStatus(this.ctx, this.name)
 	if err != nil && !errors.Is(err, dockerclient.ErrNoContainerFound) {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Error getting repo status")
@@ -213,7 +441,7 @@ func (this *apiController) Stop() error {
 	}
 	if status == nil || !status.State.Running {
 		slog.With(slog.String("repo", this.name)).InfoContext(this.ctx, "Already stopped")
-		this.startedPID = 0
+		this.setStartedPID(0)
 		return nil
 	}
 	err = dockerclient.StopContainer(this.ctx, this.name)
@@ -221,24 +449,35 @@ func (this *apiController) Stop() error {
 		slog.With(slog.Any("error", err), slog.String("repo", this.name)).InfoContext(this.ctx, "Failed to stop container")
 		return fmt.Errorf("error stopping repo: %w", err)
 	}
-	this.startedPID = 0
+	this.setStartedPID(0)
 	return nil
 }
 
-func (this *apiController) mysqlUp() error {
-	mysqlName := this.name + "-mysql"
-	status, err := dockerclient.GetStatus(this.ctx, mysqlName)
+func mysqlContainerName(repoName string) string {
+	return repoName + "-mysql"
+}
+
+func (this *apiController) isMysqlRunning() (bool, error) {
+	status, err := dockerclient.GetStatus(this.ctx, mysqlContainerName(this.name))
 	if err != nil && !errors.Is(err, dockerclient.ErrNoContainerFound) {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Error getting repo status")
-		return fmt.Errorf("error getting repo status: %w", err)
+		return false, fmt.Errorf("error getting repo status: %w", err)
 	}
-	if status != nil && status.State.Running {
+	return status != nil && status.State != nil && status.State.Running, nil
+}
+
+func (this *apiController) mysqlUp(opts StartOptions) error {
+	running, err := this.isMysqlRunning()
+	if err != nil {
+		return err
+	}
+	if running {
 		slog.With(slog.String("repo", this.name)).InfoContext(this.ctx, "Mysql already running")
 		return nil
 	}
 
 	buf := bytes.NewBufferString("")
-	err = mage.ExecWait(this.ctx, this.path, buf, "mysqlup")
+	err = mage.ExecWait(this.ctx, this.mageOptions(opts, buf), "mysqlup")
 	if err != nil {
 		slog.With(slog.Any("error", err), slog.String("out", buf.String())).ErrorContext(this.ctx, "Failed to start mysql")
 		return fmt.Errorf("failed to start mysql: %w", err)
@@ -246,3 +485,12 @@ func (this *apiController) mysqlUp() error {
 
 	return nil
 }
+
+func (this *apiController) mageOptions(opts StartOptions, logTo io.Writer) mage.Options {
+	return mage.Options{
+		Dir:      this.path,
+		RepoName: this.name,
+		Env:      opts.Env,
+		LogTo:    logTo,
+	}
+}
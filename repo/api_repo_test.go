@@ -0,0 +1,26 @@
+package repo
+
+import "testing"
+
+func TestReconciledPrevious(t *testing.T) {
+	running := Status{State: StateRunning}
+	stopped := Status{State: StateStopped}
+	unknown := Status{State: StateUnknown}
+	tests := []struct {
+		desc      string
+		latest    Status
+		lastKnown Status
+		want      Status
+	}{
+		{"no outage", running, Status{}, running},
+		{"back after an outage", unknown, running, running},
+		{"docker unavailable since launch", unknown, Status{}, unknown},
+		{"first refresh after launch", Status{}, Status{}, Status{}},
+		{"stale last known status is ignored once refreshed", stopped, running, stopped},
+	}
+	for _, test := range tests {
+		if got := reconciledPrevious(test.latest, test.lastKnown); got != test.want {
+			t.Errorf("%s: reconciledPrevious() = %v, want %v", test.desc, got, test.want)
+		}
+	}
+}
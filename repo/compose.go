@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"phaas-localservices-ui/dockerclient"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var composeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	ContainerName string       `yaml:"container_name"`
+	Ports         []any        `yaml:"ports"`
+	Build         composeBuild `yaml:"build"`
+}
+
+// composeBuild is a service's build section, given either as the context path or as a mapping
+type composeBuild struct {
+	Context    string      `yaml:"context"`
+	Dockerfile string      `yaml:"dockerfile"`
+	Args       composeArgs `yaml:"args"`
+	Target     string      `yaml:"target"`
+}
+
+func (this *composeBuild) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&this.Context)
+	}
+	type plain composeBuild
+	err := node.Decode((*plain)(this))
+	if err == nil && this.Context == "" {
+		this.Context = "."
+	}
+	return err
+}
+
+// composeArgs are build args, given either as a mapping or as a list of KEY=value entries. An arg without a value
+// takes it from the environment, like docker compose does, and is nil when that isn't set either.
+type composeArgs map[string]*string
+
+func (this *composeArgs) UnmarshalYAML(node *yaml.Node) error {
+	*this = composeArgs{}
+	if node.Kind == yaml.SequenceNode {
+		var entries []string
+		err := node.Decode(&entries)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			key, value, found := strings.Cut(entry, "=")
+			if found {
+				(*this)[key] = &value
+			} else {
+				(*this)[key] = lookupEnv(key)
+			}
+		}
+		return nil
+	}
+	var entries map[string]*string
+	err := node.Decode(&entries)
+	if err != nil {
+		return err
+	}
+	for key, value := range entries {
+		if value == nil {
+			value = lookupEnv(key)
+		}
+		(*this)[key] = value
+	}
+	return nil
+}
+
+func lookupEnv(key string) *string {
+	value, found := os.LookupEnv(key)
+	if !found {
+		return nil
+	}
+	return &value
+}
+
+// findComposeService finds the service of a container in the compose file in dir, by its container_name or service
+// name. With onlyServiceMatches set, a file with a single service matches whatever the container is called.
+func findComposeService(dir string, containerName string, onlyServiceMatches bool) (composeService, bool, error) {
+	for _, fileName := range composeFileNames {
+		content, err := os.ReadFile(filepath.Join(dir, fileName))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return composeService{}, false, fmt.Errorf("failed to read %s: %w", fileName, err)
+		}
+		var compose composeFile
+		err = yaml.Unmarshal(content, &compose)
+		if err != nil {
+			return composeService{}, false, fmt.Errorf("failed to parse %s: %w", fileName, err)
+		}
+		for name, service := range compose.Services {
+			onlyService := len(compose.Services) == 1 && onlyServiceMatches
+			if name == containerName || service.ContainerName == containerName || onlyService {
+				return service, true, nil
+			}
+		}
+		return composeService{}, false, nil
+	}
+	return composeService{}, false, nil
+}
+
+// ComposeBuildOptions reads how the repo's compose file builds its API image: the context, Dockerfile, build args and
+// target. found is false when the repo has no compose file or its service isn't built from source.
+func ComposeBuildOptions(repoPath string, repoName string) (opts dockerclient.BuildOptions, found bool, err error) {
+	service, found, err := findComposeService(repoPath, repoName, true)
+	if err != nil || !found || service.Build.Context == "" {
+		return dockerclient.BuildOptions{}, false, err
+	}
+	opts.Dir = service.Build.Context
+	if !filepath.IsAbs(opts.Dir) {
+		opts.Dir = filepath.Join(repoPath, opts.Dir)
+	}
+	opts.Dockerfile = service.Build.Dockerfile
+	if opts.Dockerfile == "" {
+		opts.Dockerfile = "Dockerfile"
+	}
+	opts.BuildArgs = service.Build.Args
+	opts.Target = service.Build.Target
+	return opts, true, nil
+}
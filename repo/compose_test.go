@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCompose(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestComposeBuildOptions(t *testing.T) {
+	t.Setenv("NPM_TOKEN", "from-env")
+	dir := writeCompose(t, `
+services:
+  billing-api:
+    container_name: phaas-billing-api
+    build:
+      context: ./service
+      dockerfile: docker/Dockerfile
+      target: runtime
+      args:
+        - VERSION=1.2.3
+        - NPM_TOKEN
+        - UNSET_ARG
+  billing-api-mysql:
+    image: mysql:8
+`)
+
+	opts, found, err := ComposeBuildOptions(dir, "phaas-billing-api")
+	if err != nil || !found {
+		t.Fatalf("ComposeBuildOptions() = %v, %v", found, err)
+	}
+	if want := filepath.Join(dir, "service"); opts.Dir != want {
+		t.Errorf("Dir = %q, want %q", opts.Dir, want)
+	}
+	if opts.Dockerfile != "docker/Dockerfile" || opts.Target != "runtime" {
+		t.Errorf("Dockerfile, Target = %q, %q", opts.Dockerfile, opts.Target)
+	}
+	if v := opts.BuildArgs["VERSION"]; v == nil || *v != "1.2.3" {
+		t.Errorf("VERSION = %v, want 1.2.3", v)
+	}
+	if v := opts.BuildArgs["NPM_TOKEN"]; v == nil || *v != "from-env" {
+		t.Errorf("NPM_TOKEN = %v, want the environment's value", v)
+	}
+	if v, ok := opts.BuildArgs["UNSET_ARG"]; !ok || v != nil {
+		t.Errorf("UNSET_ARG = %v, %t, want nil", v, ok)
+	}
+}
+
+func TestComposeBuildOptionsShortForm(t *testing.T) {
+	dir := writeCompose(t, `
+services:
+  api:
+    build: .
+    ports:
+      - "8080:8080"
+`)
+
+	opts, found, err := ComposeBuildOptions(dir, "phaas-users-api")
+	if err != nil || !found {
+		t.Fatalf("ComposeBuildOptions() = %v, %v", found, err)
+	}
+	if opts.Dir != dir || opts.Dockerfile != "Dockerfile" {
+		t.Errorf("Dir, Dockerfile = %q, %q, want %q, Dockerfile", opts.Dir, opts.Dockerfile, dir)
+	}
+}
+
+func TestComposeBuildOptionsImageOnly(t *testing.T) {
+	dir := writeCompose(t, `
+services:
+  api:
+    image: registry.example.com/users-api:latest
+`)
+
+	_, found, err := ComposeBuildOptions(dir, "phaas-users-api")
+	if err != nil || found {
+		t.Errorf("ComposeBuildOptions() = %v, %v, want not found", found, err)
+	}
+}
@@ -0,0 +1,205 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/dockerclient"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	mysqlPort    = "3306/tcp"
+	maxQueryRows = 1000
+)
+
+// queryTimeout bounds ad hoc queries. max_execution_time stops long SELECTs server side and the context abandons
+// anything else, e.g. a SLEEP() outside a SELECT.
+const queryTimeout = 30 * time.Second
+
+var (
+	ErrDatabaseNotRunning = errors.New("database container is not running")
+	ErrNoDatabase         = errors.New("database container has no MYSQL_DATABASE configured")
+	ErrQueryNotReadOnly   = errors.New("only single read-only statements are allowed")
+
+	readOnlyStatement = regexp.MustCompile(`(?i)^\s*(select|show|describe|desc|explain|with)\b`)
+	// lockingOrWriting catches clauses that let an otherwise read-only statement write files or variables or take
+	// locks. Matching inside string literals too is deliberate, it errs on the side of refusing.
+	lockingOrWriting = regexp.MustCompile(`(?i)\b(into|for\s+update|for\s+share|lock\s+in\s+share\s+mode)\b`)
+)
+
+type DatabaseInfo struct {
+	Container string `json:"container"`
+	Host      string `json:"host"`
+	Port      string `json:"port"`
+	User      string `json:"user"`
+	// Password is redacted unless it was asked for explicitly
+	Password string `json:"password"`
+	Database string `json:"database"`
+	// ConnectionString is a mysql:// URL for the published port, with the password redacted like Password
+	ConnectionString string `json:"connectionString"`
+}
+
+type QueryResult struct {
+	Columns []string `json:"columns"`
+	// Rows hold nil for NULL values
+	Rows [][]*string `json:"rows"`
+	// Truncated is set when the query returned more rows than are kept
+	Truncated bool `json:"truncated"`
+}
+
+// GetDatabaseInfo reads the published port and credentials of the repo's mysql container from its config. The password
+// is only included when revealPassword is set, e.g. for a copy button, so it isn't handed out with every view.
+func (this *apiController) GetDatabaseInfo(revealPassword bool) (DatabaseInfo, error) {
+	info, err := this.databaseInfo()
+	if err != nil || revealPassword {
+		return info, err
+	}
+	info.Password = app.RedactedSecretValue
+	info.ConnectionString = connectionString(info)
+	return info, nil
+}
+
+func (this *apiController) databaseInfo() (DatabaseInfo, error) {
+	containerName := mysqlContainerName(this.name)
+	status, err := dockerclient.GetStatus(this.ctx, containerName)
+	if err != nil {
+		return DatabaseInfo{}, fmt.Errorf("failed to get database container: %w", err)
+	}
+	if status == nil || status.State == nil || !status.State.Running {
+		return DatabaseInfo{}, ErrDatabaseNotRunning
+	}
+
+	env := map[string]string{}
+	if status.Config != nil {
+		for _, entry := range status.Config.Env {
+			key, value, _ := strings.Cut(entry, "=")
+			env[key] = value
+		}
+	}
+	info := DatabaseInfo{
+		Container: containerName,
+		Host:      "127.0.0.1",
+		Database:  env["MYSQL_DATABASE"],
+	}
+	// root can dump and restore everything, so it's preferred when its password is known
+	if password, ok := env["MYSQL_ROOT_PASSWORD"]; ok || env["MYSQL_ALLOW_EMPTY_PASSWORD"] != "" {
+		info.User = "root"
+		info.Password = password
+	} else {
+		info.User = env["MYSQL_USER"]
+		info.Password = env["MYSQL_PASSWORD"]
+	}
+	if status.NetworkSettings != nil {
+		for _, binding := range status.NetworkSettings.Ports[mysqlPort] {
+			info.Port = binding.HostPort
+			if binding.HostIP != "" && binding.HostIP != "0.0.0.0" && binding.HostIP != "::" {
+				info.Host = binding.HostIP
+			}
+			break
+		}
+	}
+	info.ConnectionString = connectionString(info)
+	return info, nil
+}
+
+// connectionString builds the mysql:// URL for the info, escaping the credentials and database name
+func connectionString(info DatabaseInfo) string {
+	if info.Port == "" {
+		return ""
+	}
+	connection := url.URL{
+		Scheme: "mysql",
+		User:   url.UserPassword(info.User, info.Password),
+		Host:   net.JoinHostPort(info.Host, info.Port),
+		Path:   "/" + info.Database,
+	}
+	return connection.String()
+}
+
+// mysqlExec runs a mysql client command in the database container, authenticating with the container's credentials
+func (this *apiController) mysqlExec(ctx context.Context, info DatabaseInfo, cmd []string, opts dockerclient.ExecOptions) error {
+	stderr := &bytes.Buffer{}
+	opts.Cmd = append([]string{cmd[0], "--user=" + info.User}, cmd[1:]...)
+	opts.Env = []string{"MYSQL_PWD=" + info.Password}
+	opts.Stderr = stderr
+	exitCode, err := dockerclient.Exec(ctx, info.Container, opts)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("%s exited with code %d: %s", cmd[0], exitCode, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// QueryDatabase runs a single read-only statement against the repo's database. It runs in a read-only transaction
+// that is rolled back, so statements that slip past the check still can't change data, and is cut off after
+// queryTimeout.
+func (this *apiController) QueryDatabase(query string) (QueryResult, error) {
+	query = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	if !isReadOnlyQuery(query) {
+		return QueryResult{}, ErrQueryNotReadOnly
+	}
+	info, err := this.databaseInfo()
+	if err != nil {
+		return QueryResult{}, err
+	}
+	ctx, cancel := context.WithTimeout(this.ctx, queryTimeout)
+	defer cancel()
+	cmd := []string{"mysql", "--batch"}
+	if info.Database != "" {
+		cmd = append(cmd, "--database="+info.Database)
+	}
+	cmd = append(cmd, fmt.Sprintf("--execute=SET SESSION max_execution_time = %d; SET SESSION TRANSACTION READ ONLY; "+
+		"START TRANSACTION READ ONLY; %s; ROLLBACK;", queryTimeout.Milliseconds(), query))
+	stdout := &bytes.Buffer{}
+	err = this.mysqlExec(ctx, info, cmd, dockerclient.ExecOptions{Stdout: stdout})
+	if err != nil {
+		return QueryResult{}, fmt.Errorf("query failed: %w", err)
+	}
+	return parseBatchOutput(stdout.String()), nil
+}
+
+func isReadOnlyQuery(query string) bool {
+	return readOnlyStatement.MatchString(query) && !strings.Contains(query, ";") && !lockingOrWriting.MatchString(query)
+}
+
+// parseBatchOutput parses the tab separated output of mysql --batch, where the first line holds the column names
+func parseBatchOutput(output string) QueryResult {
+	result := QueryResult{Columns: []string{}, Rows: [][]*string{}}
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return result
+	}
+	result.Columns = strings.Split(lines[0], "\t")
+	for _, line := range lines[1:] {
+		if len(result.Rows) >= maxQueryRows {
+			result.Truncated = true
+			break
+		}
+		fields := strings.Split(line, "\t")
+		row := make([]*string, len(fields))
+		for i, field := range fields {
+			if field == "NULL" {
+				continue
+			}
+			value := unescapeBatchField(field)
+			row[i] = &value
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result
+}
+
+var batchEscapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n", `\0`, "\x00")
+
+func unescapeBatchField(field string) string {
+	return batchEscapes.Replace(field)
+}
@@ -0,0 +1,236 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"phaas-localservices-ui/dockerclient"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	dumpsDirName      = "dumps"
+	snapshotsDirName  = "snapshots"
+	dumpSuffix        = ".sql"
+	dumpNameTimestamp = "20060102T150405"
+)
+
+var (
+	ErrInvalidDumpName = errors.New("invalid dump name")
+	ErrDumpExists      = errors.New("dump already exists")
+	ErrDumpNotFound    = errors.New("dump not found")
+
+	dumpNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+type DumpFile struct {
+	// Name is the name the dump was saved under, without the .sql suffix
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	SizeBytes int64     `json:"sizeBytes"`
+}
+
+// dumpStore keeps named dumps of the repo's database in one directory of its data dir. Plain dumps and snapshots are
+// both dump stores, they differ only in the directory and in what unnamed dumps are called.
+type dumpStore struct {
+	controller *apiController
+	dir        string
+	// kind names the dumps in logs and in the default name, e.g. "snapshot"
+	kind string
+}
+
+func (this *apiController) dumps() dumpStore {
+	return dumpStore{controller: this, dir: filepath.Join(this.repoDataPath(), dumpsDirName), kind: "dump"}
+}
+
+func (this *apiController) snapshots() dumpStore {
+	return dumpStore{controller: this, dir: filepath.Join(this.repoDataPath(), snapshotsDirName), kind: "snapshot"}
+}
+
+// dumpName checks a dump name, accepting it with or without the .sql suffix
+func dumpName(name string) (string, error) {
+	name = strings.TrimSuffix(name, dumpSuffix)
+	if !dumpNameRegex.MatchString(name) {
+		return "", fmt.Errorf("%w: '%s'", ErrInvalidDumpName, name)
+	}
+	return name, nil
+}
+
+func (this dumpStore) path(name string) string {
+	return filepath.Join(this.dir, name+dumpSuffix)
+}
+
+// create dumps the database under the name, refusing to replace an existing dump. An empty name uses a timestamped one.
+func (this dumpStore) create(name string) (DumpFile, error) {
+	if name == "" {
+		name = this.kind + "-" + time.Now().Format(dumpNameTimestamp)
+	}
+	name, err := dumpName(name)
+	if err != nil {
+		return DumpFile{}, err
+	}
+	path := this.path(name)
+	if _, err := os.Stat(path); err == nil {
+		return DumpFile{}, fmt.Errorf("%w: '%s'", ErrDumpExists, name)
+	}
+	err = this.controller.dumpDatabaseTo(path)
+	if err != nil {
+		return DumpFile{}, err
+	}
+	slog.With(slog.String("repo", this.controller.name), slog.String(this.kind, name)).InfoContext(this.controller.ctx, "Dumped database")
+	stat, err := os.Stat(path)
+	if err != nil {
+		return DumpFile{}, fmt.Errorf("failed to read dump file: %w", err)
+	}
+	return DumpFile{Name: name, CreatedAt: stat.ModTime(), SizeBytes: stat.Size()}, nil
+}
+
+// list returns the dumps in the store, newest first
+func (this dumpStore) list() ([]DumpFile, error) {
+	entries, err := os.ReadDir(this.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []DumpFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s dir: %w", this.kind, err)
+	}
+	dumps := make([]DumpFile, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), dumpSuffix)
+		if entry.IsDir() || !ok || !dumpNameRegex.MatchString(name) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		dumps = append(dumps, DumpFile{Name: name, CreatedAt: info.ModTime(), SizeBytes: info.Size()})
+	}
+	slices.SortFunc(dumps, func(a, b DumpFile) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return dumps, nil
+}
+
+func (this dumpStore) delete(name string) error {
+	name, err := dumpName(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(this.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: '%s'", ErrDumpNotFound, name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", this.kind, err)
+	}
+	slog.With(slog.String("repo", this.controller.name), slog.String(this.kind, name)).InfoContext(this.controller.ctx, "Deleted database dump")
+	return nil
+}
+
+// restore replaces the database with the named dump
+func (this dumpStore) restore(name string) error {
+	name, err := dumpName(name)
+	if err != nil {
+		return err
+	}
+	err = this.controller.restoreDatabaseFrom(this.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: '%s'", ErrDumpNotFound, name)
+	}
+	if err != nil {
+		return err
+	}
+	slog.With(slog.String("repo", this.controller.name), slog.String(this.kind, name)).InfoContext(this.controller.ctx, "Restored database")
+	return nil
+}
+
+// dumpDatabaseTo writes a mysqldump of the repo's database to the path, replacing the file only once the dump
+// succeeded
+func (this *apiController) dumpDatabaseTo(path string) error {
+	info, err := this.databaseInfo()
+	if err != nil {
+		return err
+	}
+	if info.Database == "" {
+		return ErrNoDatabase
+	}
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create dump dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".dump-*")
+	if err != nil {
+		return fmt.Errorf("failed to create dump file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	cmd := []string{"mysqldump", "--single-transaction", "--routines", "--triggers", "--no-tablespaces", "--databases", info.Database}
+	err = this.mysqlExec(this.ctx, info, cmd, dockerclient.ExecOptions{Stdout: tmp})
+	if err != nil {
+		return fmt.Errorf("dump failed: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write dump file: %w", err)
+	}
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed to save dump file: %w", err)
+	}
+	return nil
+}
+
+// restoreDatabaseFrom replaces the repo's database with a dump. The database is dropped and created again first,
+// otherwise tables added since the dump was taken would survive the restore.
+func (this *apiController) restoreDatabaseFrom(path string) error {
+	info, err := this.databaseInfo()
+	if err != nil {
+		return err
+	}
+	if info.Database == "" {
+		return ErrNoDatabase
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: '%s'", os.ErrNotExist, filepath.Base(path))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open dump file: %w", err)
+	}
+	defer file.Close()
+	// sent ahead of the dump in the same session, so a failure to drop stops the restore before anything is loaded
+	stdin := io.MultiReader(strings.NewReader(recreateDatabaseSQL(info.Database)), file)
+	err = this.mysqlExec(this.ctx, info, []string{"mysql"}, dockerclient.ExecOptions{Stdin: stdin})
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	return nil
+}
+
+func recreateDatabaseSQL(database string) string {
+	quoted := "`" + strings.ReplaceAll(database, "`", "``") + "`"
+	return fmt.Sprintf("DROP DATABASE IF EXISTS %s;\nCREATE DATABASE %s;\nUSE %s;\n", quoted, quoted, quoted)
+}
+
+// DumpDatabase dumps the repo's database to a file in its data dir. An empty name uses a timestamped one.
+func (this *apiController) DumpDatabase(name string) (DumpFile, error) {
+	return this.dumps().create(name)
+}
+
+// ListDatabaseDumps lists the dumps in the repo's data dir, newest first
+func (this *apiController) ListDatabaseDumps() ([]DumpFile, error) {
+	return this.dumps().list()
+}
+
+// RestoreDatabase replaces the repo's database with one of the dumps in its data dir
+func (this *apiController) RestoreDatabase(name string) error {
+	return this.dumps().restore(name)
+}
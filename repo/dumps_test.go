@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDumpName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"before-migration", "before-migration", false},
+		{"before-migration.sql", "before-migration", false},
+		{"v1.2_seed", "v1.2_seed", false},
+		{"", "", true},
+		{"../escape", "", true},
+		{".hidden", "", true},
+		{"with space", "", true},
+	}
+	for _, test := range tests {
+		got, err := dumpName(test.name)
+		if (err != nil) != test.wantErr {
+			t.Errorf("dumpName(%q) error = %v, wantErr %t", test.name, err, test.wantErr)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrInvalidDumpName) {
+			t.Errorf("dumpName(%q) error = %v, want ErrInvalidDumpName", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("dumpName(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRecreateDatabaseSQLQuotesName(t *testing.T) {
+	got := recreateDatabaseSQL("bill`ing")
+	want := "DROP DATABASE IF EXISTS `bill``ing`;\nCREATE DATABASE `bill``ing`;\nUSE `bill``ing`;\n"
+	if got != want {
+		t.Errorf("recreateDatabaseSQL() = %q, want %q", got, want)
+	}
+}
+
+func TestDumpStoreListAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := dumpStore{controller: &apiController{name: "billing-api"}, dir: dir, kind: "snapshot"}
+	now := time.Now()
+	files := map[string]time.Time{
+		"older.sql":  now.Add(-time.Hour),
+		"newer.sql":  now,
+		"notes.txt":  now,
+		".dump-1234": now,
+	}
+	for name, modTime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("-- dump"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dumps, err := store.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, dump := range dumps {
+		names = append(names, dump.Name)
+	}
+	if want := []string{"newer", "older"}; !slices.Equal(names, want) {
+		t.Errorf("list() names = %v, want %v", names, want)
+	}
+
+	if err := store.delete("older"); err != nil {
+		t.Fatalf("delete() error = %v", err)
+	}
+	if err := store.delete("older"); !errors.Is(err, ErrDumpNotFound) {
+		t.Errorf("second delete() error = %v, want ErrDumpNotFound", err)
+	}
+}
+
+func TestDumpStoreListMissingDir(t *testing.T) {
+	store := dumpStore{dir: filepath.Join(t.TempDir(), "missing"), kind: "dump"}
+	dumps, err := store.list()
+	if err != nil || len(dumps) != 0 {
+		t.Errorf("list() = %v, %v, want empty", dumps, err)
+	}
+}
@@ -0,0 +1,215 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"phaas-localservices-ui/dockerclient"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const knownPortsFileName = "ports.json"
+
+var ErrPortConflict = errors.New("port already in use")
+
+type PortConflict struct {
+	Port uint16
+	// Owner describes what holds the port, e.g. "container 'billing-api'" or "process 'java' (pid 4242)"
+	Owner string
+}
+
+func (this *PortConflict) Error() string {
+	return fmt.Sprintf("port %d is already in use by %s", this.Port, this.Owner)
+}
+
+func (this *PortConflict) Unwrap() error {
+	return ErrPortConflict
+}
+
+// checkPorts refuses to start when a host port the service publishes is already taken by another container or a
+// process on the host. The mysql container's ports are checked too unless it is already running, in which case it
+// holds them itself.
+func (this *apiController) checkPorts(mysqlRunning bool) error {
+	ports := this.servicePorts(this.name)
+	if !mysqlRunning {
+		for _, port := range this.servicePorts(mysqlContainerName(this.name)) {
+			if !slices.Contains(ports, port) {
+				ports = append(ports, port)
+			}
+		}
+	}
+	ownContainers := []string{this.name, mysqlContainerName(this.name)}
+	var conflicts []error
+	for _, port := range ports {
+		owner, found, err := dockerclient.FindPortPublisher(this.ctx, port, ownContainers)
+		if err != nil {
+			slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to check container ports")
+		}
+		if found {
+			conflicts = append(conflicts, &PortConflict{Port: port, Owner: fmt.Sprintf("container '%s'", owner)})
+			continue
+		}
+		if owner, inUse := hostPortOwner(port); inUse {
+			conflicts = append(conflicts, &PortConflict{Port: port, Owner: owner})
+		}
+	}
+	return errors.Join(conflicts...)
+}
+
+// servicePorts works out the host ports a container of the repo will publish, preferring the config of an existing
+// container, then the ports last seen while it was running, then the repo's compose file
+func (this *apiController) servicePorts(containerName string) []uint16 {
+	inspection, err := dockerclient.InspectAnyContainer(this.ctx, containerName)
+	if err != nil {
+		slog.With(slog.Any("error", err), slog.String("container", containerName)).WarnContext(this.ctx, "Failed to inspect container for ports")
+	}
+	if inspection != nil {
+		if ports := dockerclient.PublishedPorts(inspection); len(ports) > 0 {
+			return ports
+		}
+	}
+	if ports := this.readKnownPorts()[containerName]; len(ports) > 0 {
+		return ports
+	}
+	ports, err := this.composePorts(containerName)
+	if err != nil {
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to read ports from compose file")
+	}
+	return ports
+}
+
+func (this *apiController) knownPortsPath() string {
+	return filepath.Join(this.repoDataPath(), knownPortsFileName)
+}
+
+// readKnownPorts returns the ports last seen for each of the repo's containers, by container name
+func (this *apiController) readKnownPorts() map[string][]uint16 {
+	content, err := os.ReadFile(this.knownPortsPath())
+	if err != nil {
+		return nil
+	}
+	var ports map[string][]uint16
+	err = json.Unmarshal(content, &ports)
+	if err != nil {
+		// earlier builds kept only the service's ports as a plain list
+		var servicePorts []uint16
+		if json.Unmarshal(content, &servicePorts) == nil {
+			return map[string][]uint16{this.name: servicePorts}
+		}
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to parse known ports")
+		return nil
+	}
+	return ports
+}
+
+// rememberPorts keeps the ports of the running containers so they can be checked before the next start, when the
+// containers may no longer exist. It is called from refreshStatus when the service comes up.
+func (this *apiController) rememberPorts() {
+	ports := map[string][]uint16{}
+	for _, containerName := range []string{this.name, mysqlContainerName(this.name)} {
+		inspection, err := dockerclient.InspectAnyContainer(this.ctx, containerName)
+		if err != nil {
+			slog.With(slog.Any("error", err), slog.String("container", containerName)).WarnContext(this.ctx, "Failed to inspect container for ports")
+			return
+		}
+		if inspection != nil {
+			if published := dockerclient.PublishedPorts(inspection); len(published) > 0 {
+				ports[containerName] = published
+			}
+		}
+	}
+	if len(ports) == 0 || maps.EqualFunc(ports, this.knownPorts, slices.Equal) {
+		return
+	}
+	content, err := json.Marshal(ports)
+	if err != nil {
+		return
+	}
+	err = os.MkdirAll(this.repoDataPath(), os.ModePerm)
+	if err == nil {
+		err = os.WriteFile(this.knownPortsPath(), content, 0644)
+	}
+	if err != nil {
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to save known ports")
+		return
+	}
+	this.knownPorts = ports
+}
+
+// composePorts reads the published ports of one of the repo's containers from its compose file
+func (this *apiController) composePorts(containerName string) ([]uint16, error) {
+	service, found, err := findComposeService(this.path, containerName, containerName == this.name)
+	if err != nil || !found {
+		return nil, err
+	}
+	var ports []uint16
+	for _, entry := range service.Ports {
+		if port, ok := composeHostPort(entry); ok && !slices.Contains(ports, port) {
+			ports = append(ports, port)
+		}
+	}
+	slices.Sort(ports)
+	return ports, nil
+}
+
+// composeHostPort reads the host port of a compose ports entry, either the short "[ip:]host:container[/proto]" form
+// or the long form with a published key. Ranges and entries without a host port are skipped.
+func composeHostPort(entry any) (uint16, bool) {
+	var published string
+	switch v := entry.(type) {
+	case string:
+		v, _, _ = strings.Cut(v, "/")
+		parts := strings.Split(v, ":")
+		if len(parts) < 2 {
+			return 0, false
+		}
+		published = parts[len(parts)-2]
+	case map[string]any:
+		published = fmt.Sprint(v["published"])
+	default:
+		return 0, false
+	}
+	port, err := strconv.ParseUint(published, 10, 16)
+	if err != nil || port == 0 {
+		return 0, false
+	}
+	return uint16(port), true
+}
+
+// hostPortOwner checks whether a process on the host is listening on the port and describes it if lsof can tell
+func hostPortOwner(port uint16) (string, bool) {
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(int(port)))
+	if err == nil {
+		listener.Close()
+		return "", false
+	}
+	if !errors.Is(err, syscall.EADDRINUSE) {
+		return "", false
+	}
+	out, err := exec.Command("lsof", "-nP", "-iTCP:"+strconv.Itoa(int(port)), "-sTCP:LISTEN", "-Fpc").Output()
+	if err != nil {
+		return "another process", true
+	}
+	var pid, command string
+	for _, line := range strings.Split(string(out), "\n") {
+		switch {
+		case strings.HasPrefix(line, "p") && pid == "":
+			pid = line[1:]
+		case strings.HasPrefix(line, "c") && command == "":
+			command = line[1:]
+		}
+	}
+	if pid == "" {
+		return "another process", true
+	}
+	return fmt.Sprintf("process '%s' (pid %s)", command, pid), true
+}
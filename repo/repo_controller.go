@@ -6,6 +6,9 @@ import (
 	"log/slog"
 	"os"
 	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/dockerclient"
+	"phaas-localservices-ui/history"
+	"phaas-localservices-ui/logquery"
 	"phaas-localservices-ui/scheduler"
 	"regexp"
 	"time"
@@ -18,8 +21,39 @@ type Controller interface {
 	GetStatus() (Status, error)
 	GetStatusNotificationChannel() string
 	RegisterStatusWatcher() error
-	Start() error
-	Stop() error
+	Start(opts StartOptions) error
+	Stop(opts StopOptions) error
+	// WaitUntilStopped waits for the service to report as stopped after Stop, recording the transition as requested
+	WaitUntilStopped(timeout time.Duration) error
+	// LastStartOptions returns the options of the last Start, so a restart can keep its one-off env params
+	LastStartOptions() StartOptions
+	ListLogs() ([]LogFile, error)
+	ReadLog(name string, offset int64, limit int) (LogChunk, error)
+	QueryLog(name string, query logquery.Query) (logquery.Result, error)
+	GetDatabaseInfo(revealPassword bool) (DatabaseInfo, error)
+	QueryDatabase(query string) (QueryResult, error)
+	DumpDatabase(name string) (DumpFile, error)
+	ListDatabaseDumps() ([]DumpFile, error)
+	RestoreDatabase(name string) error
+	CreateSnapshot(name string) (DumpFile, error)
+	ListSnapshots() ([]DumpFile, error)
+	DeleteSnapshot(name string) error
+	RestoreSnapshot(name string) error
+	// DockerAvailabilityChanged reports the status as unknown while docker is unavailable and refreshes it once docker
+	// is back
+	DockerAvailabilityChanged(available bool)
+	// Close stops background work for the controller when it is dropped. It does not stop the service.
+	Close()
+}
+
+type StartOptions struct {
+	Trigger history.Trigger
+	// Env holds one-off env params that apply to this start only
+	Env []app.EnvParam
+}
+
+type StopOptions struct {
+	Trigger history.Trigger
 }
 
 type State string
@@ -51,30 +85,64 @@ type BasicDetails struct {
 	StatusNotificationChannel string `json:"statusNotificationChannel"`
 }
 
+// Transition describes a change in a repo's status as seen by its status watcher
+type Transition struct {
+	Repo string `json:"repo"`
+	From State  `json:"from"`
+	To   State  `json:"to"`
+	// Unexpected is set when the repo stopped without Stop being called
+	Unexpected bool `json:"unexpected"`
+}
+
+type TransitionListener func(Transition)
+
 type Factory struct {
 	settings     *app.Settings
 	jobScheduler *scheduler.Scheduler
+	history      *history.Store
+	docker       *dockerclient.Monitor
+
+	transitionListeners []TransitionListener
 }
 
 func NewFactory(
 	settings *app.Settings,
 	jobScheduler *scheduler.Scheduler,
+	historyStore *history.Store,
+	dockerMonitor *dockerclient.Monitor,
 ) *Factory {
 	return &Factory{
 		settings:     settings,
 		jobScheduler: jobScheduler,
+		history:      historyStore,
+		docker:       dockerMonitor,
 	}
 }
 
 var apiRegex = regexp.MustCompile("phaas-.*-api")
 var uiRegex = regexp.MustCompile("phaas-.*-ui")
 
+// AddTransitionListener registers a listener that is called on every status transition of every repo controller
+// built by the factory. Listeners must be added before the controllers start watching their status.
+func (this *Factory) AddTransitionListener(listener TransitionListener) {
+	this.transitionListeners = append(this.transitionListeners, listener)
+}
+
+func (this *Factory) emitTransition(transition Transition) {
+	for _, listener := range this.transitionListeners {
+		listener(transition)
+	}
+}
+
 func (this *Factory) BuildRepoController(ctx context.Context, path string, name string, dir os.DirEntry) Controller {
 	if apiRegex.MatchString(name) {
 		return &apiController{
 			ctx:          slogctx.Append(ctx, slog.String("repo", name)),
 			appSettings:  this.settings,
 			jobScheduler: this.jobScheduler,
+			history:      this.history,
+			docker:       this.docker,
+			transitions:  this.emitTransition,
 			name:         name,
 			path:         path,
 			dir:          dir,
@@ -0,0 +1,150 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/dockerclient"
+	"phaas-localservices-ui/history"
+	"time"
+)
+
+const (
+	restartBaseDelay = 2 * time.Second
+	restartMaxDelay  = time.Minute
+
+	// startFailedExitCode stands in for the exit code when an automatic restart couldn't start the service at all,
+	// so on-failure policies treat it as a failure
+	startFailedExitCode = -1
+
+	// a service that stayed up at least this long before crashing gets a fresh set of retries
+	restartResetAfter = 5 * time.Minute
+)
+
+// handleStopped is called when the status watcher sees the service go from running to stopped.
+// Stops requested through Stop() are ignored, anything else is treated as a crash and restarted
+// according to the repo's restart policy.
+func (this *apiController) handleStopped() {
+	this.restartMutex.Lock()
+	defer this.restartMutex.Unlock()
+
+	if this.stopRequested {
+		this.stopRequested = false
+		return
+	}
+
+	exitCode, err := this.lastExitCode()
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to get exit code of crashed container")
+	}
+	slog.With(slog.Int("exitCode", exitCode), slog.Time("runningSince", this.runningSince)).
+		WarnContext(this.ctx, "Service stopped unexpectedly")
+	this.recordHistory(history.Entry{
+		Event:  history.EventCrash,
+		Reason: fmt.Sprintf("container stopped unexpectedly with exit code %d", exitCode),
+	})
+
+	if !this.runningSince.IsZero() && time.Since(this.runningSince) > restartResetAfter {
+		this.restartAttempts = 0
+	}
+	this.scheduleRestart(exitCode)
+}
+
+// scheduleRestart schedules the next restart with backoff if the repo's restart policy allows another one. It must be
+// called with restartMutex held.
+func (this *apiController) scheduleRestart(exitCode int) {
+	policy := app.RepoSettingsOf(this.appSettings, this.name).RestartPolicy
+	if !shouldRestart(policy, exitCode, this.restartAttempts) {
+		slog.With(slog.String("mode", string(policy.Mode)), slog.Int("attempts", this.restartAttempts)).
+			InfoContext(this.ctx, "Restart policy does not allow a restart")
+		return
+	}
+
+	delay := restartDelay(this.restartAttempts)
+	this.restartAttempts++
+	slog.With(slog.Int("attempt", this.restartAttempts), slog.Duration("delay", delay)).InfoContext(this.ctx, "Scheduling restart")
+	this.cancelPendingRestart()
+	generation := this.restartGeneration
+	this.pendingRestart = time.AfterFunc(delay, func() {
+		this.autoRestart(generation)
+	})
+}
+
+// shouldRestart reports whether the policy allows another restart of a service that exited with exitCode after
+// attempts restarts in a row
+func shouldRestart(policy app.RestartPolicy, exitCode int, attempts int) bool {
+	switch policy.Mode {
+	case app.RestartAlways:
+		return true
+	case app.RestartOnFailure:
+		if exitCode == 0 {
+			return false
+		}
+		return policy.MaxRetries <= 0 || attempts < policy.MaxRetries
+	default:
+		return false
+	}
+}
+
+// restartDelay doubles the delay with every attempt, starting at restartBaseDelay and capped at restartMaxDelay
+func restartDelay(attempts int) time.Duration {
+	if attempts < 0 {
+		attempts = 0
+	}
+	delay := restartBaseDelay << attempts
+	if delay > restartMaxDelay || delay <= 0 {
+		delay = restartMaxDelay
+	}
+	return delay
+}
+
+// autoRestart runs when a scheduled restart fires. The generation guards against a timer that fired while Start or
+// Stop was cancelling it, in which case the restart no longer applies.
+func (this *apiController) autoRestart(generation int) {
+	this.restartMutex.Lock()
+	if this.pendingRestart == nil || generation != this.restartGeneration {
+		this.restartMutex.Unlock()
+		return
+	}
+	this.pendingRestart = nil
+	opts := this.lastStartOptions
+	this.restartMutex.Unlock()
+
+	opts.Trigger = history.TriggerAutoRestart
+	err := this.start(opts)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to restart service")
+		this.restartMutex.Lock()
+		defer this.restartMutex.Unlock()
+		// a Start or Stop in the meantime takes over from the automatic restarts
+		if generation != this.restartGeneration {
+			return
+		}
+		// the failed attempt counts against the policy like a crash would, so a start that keeps failing backs off
+		// and eventually gives up
+		this.scheduleRestart(startFailedExitCode)
+	}
+}
+
+// cancelPendingRestart must be called with restartMutex held. It also moves on the generation, so a restart that is
+// already running doesn't schedule another one when it fails.
+func (this *apiController) cancelPendingRestart() {
+	this.restartGeneration++
+	if this.pendingRestart != nil {
+		this.pendingRestart.Stop()
+		this.pendingRestart = nil
+	}
+}
+
+// lastExitCode returns the exit code of the stopped container, or -1 if the container is gone and the code is unknown
+func (this *apiController) lastExitCode() (int, error) {
+	status, err := dockerclient.GetStatus(this.ctx, this.name)
+	if err != nil && !errors.Is(err, dockerclient.ErrNoContainerFound) {
+		return -1, err
+	}
+	if status == nil || status.State == nil {
+		return -1, nil
+	}
+	return status.State.ExitCode, nil
+}
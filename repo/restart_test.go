@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"phaas-localservices-ui/app"
+	"testing"
+	"time"
+)
+
+func TestRestartDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{-1, restartBaseDelay},
+		{0, restartBaseDelay},
+		{1, 2 * restartBaseDelay},
+		{3, 8 * restartBaseDelay},
+		{5, restartMaxDelay},
+		{64, restartMaxDelay},
+		{1000, restartMaxDelay},
+	}
+	for _, test := range tests {
+		got := restartDelay(test.attempts)
+		if got != test.want {
+			t.Errorf("restartDelay(%d) = %s, want %s", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestShouldRestart(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   app.RestartPolicy
+		exitCode int
+		attempts int
+		want     bool
+	}{
+		{"never", app.RestartPolicy{Mode: app.RestartNever}, 1, 0, false},
+		{"unset mode", app.RestartPolicy{}, 1, 0, false},
+		{"always after clean exit", app.RestartPolicy{Mode: app.RestartAlways}, 0, 10, true},
+		{"on failure after clean exit", app.RestartPolicy{Mode: app.RestartOnFailure}, 0, 0, false},
+		{"on failure unlimited", app.RestartPolicy{Mode: app.RestartOnFailure}, 1, 100, true},
+		{"on failure unknown exit code", app.RestartPolicy{Mode: app.RestartOnFailure}, -1, 0, true},
+		{"on failure under max", app.RestartPolicy{Mode: app.RestartOnFailure, MaxRetries: 3}, 1, 2, true},
+		{"on failure at max", app.RestartPolicy{Mode: app.RestartOnFailure, MaxRetries: 3}, 1, 3, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := shouldRestart(test.policy, test.exitCode, test.attempts)
+			if got != test.want {
+				t.Errorf("shouldRestart() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/logquery"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	serviceLogPrefix      = "service-"
+	serviceLogSuffix      = ".log"
+	serviceLogTimeFormat  = "20060102T150405.000"
+	currentServiceLogLink = "current"
+
+	maxLogReadBytes = 1 << 20
+)
+
+type LogFile struct {
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"startedAt"`
+	SizeBytes int64     `json:"sizeBytes"`
+	// Current is set for the log of the latest run
+	Current bool `json:"current"`
+}
+
+type LogChunk struct {
+	Content    string `json:"content"`
+	NextOffset int64  `json:"nextOffset"`
+	EOF        bool   `json:"eof"`
+}
+
+var ErrLogNotFound = errors.New("log file not found")
+
+func (this *apiController) repoDataPath() string {
+	return filepath.Join(this.appSettings.DataDirPath, this.name)
+}
+
+func (this *apiController) logsDirPath() string {
+	return filepath.Join(this.repoDataPath(), "logs")
+}
+
+// createRunLog creates a new timestamped log file for a run of the service, points the current link at it and
+// applies the retention settings to older runs
+func (this *apiController) createRunLog() (*os.File, error) {
+	logsDir := this.logsDirPath()
+	err := os.MkdirAll(logsDir, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logs dir: %w", err)
+	}
+	name := serviceLogPrefix + time.Now().Format(serviceLogTimeFormat) + serviceLogSuffix
+	logFile, err := os.Create(filepath.Join(logsDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+
+	linkPath := filepath.Join(logsDir, currentServiceLogLink)
+	err = os.Remove(linkPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to remove current log link")
+	}
+	err = os.Symlink(name, linkPath)
+	if err != nil {
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to link current log")
+	}
+
+	err = this.pruneRunLogs()
+	if err != nil {
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to prune old service logs")
+	}
+	return logFile, nil
+}
+
+// ListLogs returns the logs of past runs, newest first
+func (this *apiController) ListLogs() ([]LogFile, error) {
+	entries, err := os.ReadDir(this.logsDirPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return []LogFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read logs dir: %w", err)
+	}
+	logs := make([]LogFile, 0, len(entries))
+	for _, entry := range entries {
+		startedAt, ok := parseRunLogName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, LogFile{
+			Name:      entry.Name(),
+			StartedAt: startedAt,
+			SizeBytes: info.Size(),
+		})
+	}
+	slices.SortFunc(logs, func(a, b LogFile) int {
+		return b.StartedAt.Compare(a.StartedAt)
+	})
+	if len(logs) > 0 {
+		logs[0].Current = true
+	}
+	return logs, nil
+}
+
+// ReadLog reads up to limit bytes of the named run log starting at offset. An empty name reads the current log.
+func (this *apiController) ReadLog(name string, offset int64, limit int) (LogChunk, error) {
+	path, err := this.runLogPath(name)
+	if err != nil {
+		return LogChunk{}, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return LogChunk{}, ErrLogNotFound
+	}
+	if err != nil {
+		return LogChunk{}, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	if limit <= 0 || limit > maxLogReadBytes {
+		limit = maxLogReadBytes
+	}
+	buf := make([]byte, limit)
+	n, err := file.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return LogChunk{}, fmt.Errorf("failed to read log file: %w", err)
+	}
+	return LogChunk{
+		Content:    string(buf[:n]),
+		NextOffset: offset + int64(n),
+		EOF:        errors.Is(err, io.EOF),
+	}, nil
+}
+
+// QueryLog searches the named run log and returns a page of the matching lines. An empty name searches the current
+// log.
+func (this *apiController) QueryLog(name string, query logquery.Query) (logquery.Result, error) {
+	path, err := this.runLogPath(name)
+	if err != nil {
+		return logquery.Result{}, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return logquery.Result{}, ErrLogNotFound
+	}
+	if err != nil {
+		return logquery.Result{}, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+	return logquery.Run(file, query)
+}
+
+// runLogPath resolves a run log name to its path, refusing anything that isn't a run log in the logs dir
+func (this *apiController) runLogPath(name string) (string, error) {
+	if name == "" {
+		logs, err := this.ListLogs()
+		if err != nil {
+			return "", err
+		}
+		if len(logs) == 0 {
+			return "", ErrLogNotFound
+		}
+		name = logs[0].Name
+	}
+	if _, ok := parseRunLogName(name); !ok || filepath.Base(name) != name {
+		return "", fmt.Errorf("%w: '%s'", ErrLogNotFound, name)
+	}
+	return filepath.Join(this.logsDirPath(), name), nil
+}
+
+func parseRunLogName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, serviceLogPrefix) || !strings.HasSuffix(name, serviceLogSuffix) {
+		return time.Time{}, false
+	}
+	timestamp := strings.TrimSuffix(strings.TrimPrefix(name, serviceLogPrefix), serviceLogSuffix)
+	startedAt, err := time.ParseInLocation(serviceLogTimeFormat, timestamp, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return startedAt, true
+}
+
+// pruneRunLogs deletes the oldest run logs beyond the retention limits. The current run's log is always kept.
+func (this *apiController) pruneRunLogs() error {
+	retention := app.ServiceLogRetentionOf(this.appSettings)
+	logs, err := this.ListLogs()
+	if err != nil {
+		return err
+	}
+	var totalBytes int64
+	maxBytes := int64(retention.MaxTotalSizeMB) << 20
+	var errs []error
+	for i, log := range logs {
+		totalBytes += log.SizeBytes
+		if i == 0 || (i < retention.MaxRuns && totalBytes <= maxBytes) {
+			continue
+		}
+		err := os.Remove(filepath.Join(this.logsDirPath(), log.Name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"phaas-localservices-ui/app"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLogsController(t *testing.T, retention app.ServiceLogRetention) *apiController {
+	t.Helper()
+	settings := app.NewSettings()
+	settings.DataDirPath = t.TempDir()
+	settings.ServiceLogs = retention
+	controller := &apiController{name: "phaas-billing-api", appSettings: settings}
+	if err := os.MkdirAll(controller.logsDirPath(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return controller
+}
+
+// writeRunLogs writes one run log per size, the first being the newest, and returns their names in the same order
+func writeRunLogs(t *testing.T, controller *apiController, sizes ...int) []string {
+	t.Helper()
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+	names := make([]string, len(sizes))
+	for i, size := range sizes {
+		startedAt := start.Add(-time.Duration(i) * time.Minute)
+		names[i] = serviceLogPrefix + startedAt.Format(serviceLogTimeFormat) + serviceLogSuffix
+		content := []byte(strings.Repeat("x", size))
+		if err := os.WriteFile(filepath.Join(controller.logsDirPath(), names[i]), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return names
+}
+
+func logNames(t *testing.T, controller *apiController) []string {
+	t.Helper()
+	logs, err := controller.ListLogs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(logs))
+	for i, log := range logs {
+		names[i] = log.Name
+	}
+	return names
+}
+
+func TestListLogs(t *testing.T) {
+	controller := newLogsController(t, app.ServiceLogRetention{})
+	if logs, err := controller.ListLogs(); err != nil || len(logs) != 0 {
+		t.Fatalf("ListLogs() with no logs = %v, %v", logs, err)
+	}
+
+	names := writeRunLogs(t, controller, 10, 20, 30)
+	logsDir := controller.logsDirPath()
+	if err := os.Symlink(names[0], filepath.Join(logsDir, currentServiceLogLink)); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(logsDir, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(logsDir, serviceLogPrefix+"20250101T000000.000"+serviceLogSuffix), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	logs, err := controller.ListLogs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := logNames(t, controller); !slices.Equal(got, names) {
+		t.Errorf("ListLogs() = %v, want %v newest first", got, names)
+	}
+	for i, log := range logs {
+		if log.Current != (i == 0) {
+			t.Errorf("logs[%d].Current = %t, want only the newest current", i, log.Current)
+		}
+	}
+	if logs[1].SizeBytes != 20 {
+		t.Errorf("logs[1].SizeBytes = %d, want 20", logs[1].SizeBytes)
+	}
+}
+
+func TestPruneRunLogs(t *testing.T) {
+	const mb = 1 << 20
+	tests := []struct {
+		desc      string
+		retention app.ServiceLogRetention
+		sizes     []int
+		wantKept  int
+	}{
+		{"within limits", app.ServiceLogRetention{MaxRuns: 5, MaxTotalSizeMB: 10}, []int{10, 10, 10}, 3},
+		{"capped by runs", app.ServiceLogRetention{MaxRuns: 2, MaxTotalSizeMB: 10}, []int{10, 10, 10, 10}, 2},
+		{"capped by size", app.ServiceLogRetention{MaxRuns: 5, MaxTotalSizeMB: 1}, []int{mb / 2, mb / 2, mb / 2}, 2},
+		{"current run kept over the size cap", app.ServiceLogRetention{MaxRuns: 5, MaxTotalSizeMB: 1}, []int{2 * mb, 10}, 1},
+		{"capped at a single run", app.ServiceLogRetention{MaxRuns: 1, MaxTotalSizeMB: 10}, []int{10, 10}, 1},
+	}
+	for _, test := range tests {
+		controller := newLogsController(t, test.retention)
+		names := writeRunLogs(t, controller, test.sizes...)
+
+		if err := controller.pruneRunLogs(); err != nil {
+			t.Errorf("%s: pruneRunLogs() error = %v", test.desc, err)
+			continue
+		}
+		if got := logNames(t, controller); !slices.Equal(got, names[:test.wantKept]) {
+			t.Errorf("%s: kept %v, want %v", test.desc, got, names[:test.wantKept])
+		}
+	}
+}
+
+func TestRunLogPath(t *testing.T) {
+	controller := newLogsController(t, app.ServiceLogRetention{})
+	if _, err := controller.runLogPath(""); !errors.Is(err, ErrLogNotFound) {
+		t.Errorf("runLogPath(\"\") with no logs error = %v, want ErrLogNotFound", err)
+	}
+
+	names := writeRunLogs(t, controller, 10, 10)
+	path, err := controller.runLogPath("")
+	if err != nil || path != filepath.Join(controller.logsDirPath(), names[0]) {
+		t.Errorf("runLogPath(\"\") = %q, %v, want the newest log", path, err)
+	}
+	path, err = controller.runLogPath(names[1])
+	if err != nil || path != filepath.Join(controller.logsDirPath(), names[1]) {
+		t.Errorf("runLogPath(%q) = %q, %v", names[1], path, err)
+	}
+
+	for _, name := range []string{
+		"../" + names[0],
+		"../../phaas-users-api/logs/" + names[0],
+		"nested/" + names[0],
+		"/etc/passwd",
+		currentServiceLogLink,
+		serviceLogPrefix + "yesterday" + serviceLogSuffix,
+	} {
+		if path, err := controller.runLogPath(name); !errors.Is(err, ErrLogNotFound) {
+			t.Errorf("runLogPath(%q) = %q, %v, want ErrLogNotFound", name, path, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+)
+
+// Snapshots are dumps kept apart from the plain ones, for capturing the database before something destructive like
+// a migration. Unlike plain dumps they start the mysql container when it isn't running.
+
+// CreateSnapshot dumps the repo's database into a named snapshot, starting the mysql container if needed. An empty
+// name uses a timestamped one.
+func (this *apiController) CreateSnapshot(name string) (DumpFile, error) {
+	err := this.mysqlUp(StartOptions{})
+	if err != nil {
+		return DumpFile{}, err
+	}
+	return this.snapshots().create(name)
+}
+
+// ListSnapshots lists the repo's database snapshots, newest first
+func (this *apiController) ListSnapshots() ([]DumpFile, error) {
+	return this.snapshots().list()
+}
+
+func (this *apiController) DeleteSnapshot(name string) error {
+	return this.snapshots().delete(name)
+}
+
+// RestoreSnapshot replaces the repo's database with a snapshot, starting the mysql container if needed. It doesn't
+// stop the API, callers should do that first so it doesn't see a half restored database.
+func (this *apiController) RestoreSnapshot(name string) error {
+	name, err := dumpName(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(this.snapshots().path(name)); err != nil {
+		return fmt.Errorf("%w: '%s'", ErrDumpNotFound, name)
+	}
+	err = this.mysqlUp(StartOptions{})
+	if err != nil {
+		return err
+	}
+	return this.snapshots().restore(name)
+}
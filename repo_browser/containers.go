@@ -0,0 +1,169 @@
+package repobrowser
+
+import (
+	"fmt"
+	"log/slog"
+	"phaas-localservices-ui/dockerclient"
+	"phaas-localservices-ui/history"
+	"phaas-localservices-ui/repo"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+type ContainerRole string
+
+const (
+	ContainerRoleNone  ContainerRole = ""
+	ContainerRoleAPI   ContainerRole = "api"
+	ContainerRoleMysql ContainerRole = "mysql"
+)
+
+var AllContainerRoles = []struct {
+	Value  ContainerRole
+	TSName string
+}{
+	{ContainerRoleNone, "none"},
+	{ContainerRoleAPI, "api"},
+	{ContainerRoleMysql, "mysql"},
+}
+
+type ContainerPort struct {
+	HostIP        string `json:"hostIp"`
+	HostPort      uint16 `json:"hostPort"`
+	ContainerPort uint16 `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+type ContainerView struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	// State is docker's state, e.g. running or exited, and Status its human readable detail, e.g. "Up 2 hours"
+	State  string          `json:"state"`
+	Status string          `json:"status"`
+	Ports  []ContainerPort `json:"ports"`
+	// Repo is the known repo the container belongs to, if any, and Role which of its containers it is
+	Repo string        `json:"repo"`
+	Role ContainerRole `json:"role"`
+}
+
+func containerName(c container.Summary) string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// repoOfContainer maps a container name to the known repo it belongs to
+func (this *RepoBrowser) repoOfContainer(name string) (string, ContainerRole) {
+	if _, err := this.repos.Get(name); err == nil {
+		return name, ContainerRoleAPI
+	}
+	if repoName, ok := strings.CutSuffix(name, mysqlContainerSuffix); ok {
+		if _, err := this.repos.Get(repoName); err == nil {
+			return repoName, ContainerRoleMysql
+		}
+	}
+	return "", ContainerRoleNone
+}
+
+// ListContainers lists every docker container, including ones started outside the app, sorted by name
+func (this *RepoBrowser) ListContainers() ([]ContainerView, error) {
+	containers, err := dockerclient.ListAllContainers(this.ctx)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]ContainerView, 0, len(containers))
+	for _, c := range containers {
+		view := ContainerView{
+			ID:     c.ID,
+			Name:   containerName(c),
+			Image:  c.Image,
+			State:  c.State,
+			Status: c.Status,
+			Ports:  []ContainerPort{},
+		}
+		view.Repo, view.Role = this.repoOfContainer(view.Name)
+		for _, p := range c.Ports {
+			view.Ports = append(view.Ports, ContainerPort{
+				HostIP:        p.IP,
+				HostPort:      p.PublicPort,
+				ContainerPort: p.PrivatePort,
+				Protocol:      p.Type,
+			})
+		}
+		views = append(views, view)
+	}
+	slices.SortFunc(views, func(a, b ContainerView) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return views, nil
+}
+
+func (this *RepoBrowser) getContainerView(containerID string) (ContainerView, error) {
+	views, err := this.ListContainers()
+	if err != nil {
+		return ContainerView{}, err
+	}
+	for _, view := range views {
+		if view.ID == containerID {
+			return view, nil
+		}
+	}
+	return ContainerView{}, dockerclient.ErrNoContainerFound
+}
+
+// StartContainer starts a stopped container. A repo's API container is started through the repo, with the options of
+// its last start, so the run is recorded in its history and gets its env.
+func (this *RepoBrowser) StartContainer(containerID string) error {
+	view, err := this.getContainerView(containerID)
+	if err != nil {
+		return fmt.Errorf("failed to get container: %w", err)
+	}
+	if view.Role == ContainerRoleAPI {
+		repoController, err := this.repos.Get(view.Repo)
+		if err == nil {
+			return repoController.Start(restartOptions(repoController, history.TriggerUser))
+		}
+	}
+	err = dockerclient.StartContainerByID(this.ctx, containerID)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to start container")
+		return err
+	}
+	return nil
+}
+
+// StopContainer stops a container gracefully. A repo's API container is stopped through the repo, so it is recorded
+// in its history and not restarted automatically.
+func (this *RepoBrowser) StopContainer(containerID string) error {
+	view, err := this.getContainerView(containerID)
+	if err != nil {
+		return fmt.Errorf("failed to get container: %w", err)
+	}
+	if view.Role == ContainerRoleAPI {
+		repoController, err := this.repos.Get(view.Repo)
+		if err == nil {
+			this.closeRepoTerminals(view.Repo)
+			return repoController.Stop(repo.StopOptions{Trigger: history.TriggerUser})
+		}
+	}
+	err = dockerclient.StopContainerByID(this.ctx, containerID)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to stop container")
+		return err
+	}
+	return nil
+}
+
+// RemoveContainer removes a stopped container. Its volumes are kept.
+func (this *RepoBrowser) RemoveContainer(containerID string) error {
+	err := dockerclient.RemoveContainer(this.ctx, containerID, false)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to remove container")
+		return err
+	}
+	return nil
+}
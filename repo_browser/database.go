@@ -0,0 +1,75 @@
+package repobrowser
+
+import (
+	"fmt"
+	"phaas-localservices-ui/history"
+	"phaas-localservices-ui/repo"
+)
+
+// GetRepoDatabaseInfo returns the connection details of the repo's mysql container. The password is redacted unless
+// revealPassword is set.
+func (this *RepoBrowser) GetRepoDatabaseInfo(repoName string, revealPassword bool) (repo.DatabaseInfo, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return repo.DatabaseInfo{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	info, err := repoController.GetDatabaseInfo(revealPassword)
+	if err != nil {
+		return repo.DatabaseInfo{}, fmt.Errorf("failed to get database for repo '%s': %w", repoName, err)
+	}
+	return info, nil
+}
+
+// QueryRepoDatabase runs a read-only statement against the repo's database and returns the rows
+func (this *RepoBrowser) QueryRepoDatabase(repoName string, query string) (repo.QueryResult, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return repo.QueryResult{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	result, err := repoController.QueryDatabase(query)
+	if err != nil {
+		return repo.QueryResult{}, fmt.Errorf("failed to query database for repo '%s': %w", repoName, err)
+	}
+	return result, nil
+}
+
+// DumpRepoDatabase dumps the repo's database to its data dir. An empty name uses a timestamped one.
+func (this *RepoBrowser) DumpRepoDatabase(repoName string, name string) (repo.DumpFile, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return repo.DumpFile{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	dump, err := repoController.DumpDatabase(name)
+	if err != nil {
+		return repo.DumpFile{}, fmt.Errorf("failed to dump database for repo '%s': %w", repoName, err)
+	}
+	return dump, nil
+}
+
+func (this *RepoBrowser) ListRepoDatabaseDumps(repoName string) ([]repo.DumpFile, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	dumps, err := repoController.ListDatabaseDumps()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list database dumps for repo '%s': %w", repoName, err)
+	}
+	return dumps, nil
+}
+
+// RestoreRepoDatabase replaces the repo's database with one of its dumps. Like a snapshot restore, a running API is
+// stopped first and started again afterwards.
+func (this *RepoBrowser) RestoreRepoDatabase(repoName string, name string) error {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	return this.restoreWithAPIStopped(repoController, history.TriggerDatabaseRestore, func() error {
+		err := repoController.RestoreDatabase(name)
+		if err != nil {
+			return fmt.Errorf("failed to restore database for repo '%s': %w", repoName, err)
+		}
+		return nil
+	})
+}
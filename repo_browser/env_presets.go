@@ -0,0 +1,113 @@
+package repobrowser
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/history"
+	"phaas-localservices-ui/repo"
+	"slices"
+	"strings"
+	"time"
+)
+
+const restartStopTimeout = 30 * time.Second
+
+// GetReposAffectedByEnvPreset lists the running repos whose effective env would change if the named preset became
+// active, so the user can be asked whether to restart them
+func (this *RepoBrowser) GetReposAffectedByEnvPreset(presetName string) ([]string, error) {
+	affected := make([]string, 0)
+	for name, repoController := range this.repos.List() {
+		status, err := repoController.GetStatus()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repo status for '%s': %w", name, err)
+		}
+		if status.State != repo.StateRunning && status.State != repo.StateStarting {
+			continue
+		}
+		current, err := this.settings.ResolveEnv(name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve env for '%s': %w", name, err)
+		}
+		next, err := this.settings.ResolveEnvForPreset(name, presetName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve env for '%s': %w", name, err)
+		}
+		if !slices.EqualFunc(current, next, func(a, b app.ResolvedEnvParam) bool {
+			return a.Key == b.Key && a.Value == b.Value
+		}) {
+			affected = append(affected, name)
+		}
+	}
+	slices.SortFunc(affected, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return affected, nil
+}
+
+// SwitchEnvPreset activates the named preset and returns the running repos affected by the change. When
+// restartAffected is set those repos are restarted so they pick up the new env.
+func (this *RepoBrowser) SwitchEnvPreset(presetName string, restartAffected bool) ([]string, error) {
+	affected, err := this.GetReposAffectedByEnvPreset(presetName)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "failed to find repos affected by env preset")
+		return nil, fmt.Errorf("failed to find repos affected by env preset: %w", err)
+	}
+	err = this.settings.SetActiveEnvPreset(presetName)
+	if err != nil {
+		return nil, err
+	}
+	if !restartAffected {
+		return affected, nil
+	}
+
+	var errs []error
+	for _, name := range affected {
+		err := this.restartRepo(name, history.TriggerEnvPreset)
+		if err != nil {
+			slog.With(slog.Any("error", err), slog.String("repo", name)).ErrorContext(this.ctx, "failed to restart repo after env preset change")
+			errs = append(errs, err)
+		}
+	}
+	return affected, errors.Join(errs...)
+}
+
+func (this *RepoBrowser) restartRepo(repoName string, trigger history.Trigger) error {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	err = this.stopAndWait(repoController, trigger)
+	if err != nil {
+		return err
+	}
+	err = repoController.Start(restartOptions(repoController, trigger))
+	if err != nil {
+		return fmt.Errorf("failed to start repo '%s': %w", repoName, err)
+	}
+	return nil
+}
+
+// restartOptions reuses the options of the repo's last start, so one-off env params survive the restart
+func restartOptions(repoController repo.Controller, trigger history.Trigger) repo.StartOptions {
+	opts := repoController.LastStartOptions()
+	opts.Trigger = trigger
+	return opts
+}
+
+// stopAndWait closes the repo's terminals, stops it and waits for its container to report as stopped, so it can be
+// started again
+func (this *RepoBrowser) stopAndWait(repoController repo.Controller, trigger history.Trigger) error {
+	repoName := repoController.GetBasicDetails().Name
+	this.closeRepoTerminals(repoName)
+	err := repoController.Stop(repo.StopOptions{Trigger: trigger})
+	if err != nil {
+		return fmt.Errorf("failed to stop repo '%s': %w", repoName, err)
+	}
+	err = repoController.WaitUntilStopped(restartStopTimeout)
+	if err != nil {
+		return fmt.Errorf("failed waiting for repo '%s' to stop: %w", repoName, err)
+	}
+	return nil
+}
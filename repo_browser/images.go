@@ -0,0 +1,206 @@
+package repobrowser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"phaas-localservices-ui/dockerclient"
+	"phaas-localservices-ui/repo"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	ImageProgressEvent = "image-progress"
+
+	// repoImageLabel marks images built by the app, so dangling ones can be pruned without touching others
+	repoImageLabel = "phaas.repo"
+	dockerfileName = "Dockerfile"
+)
+
+type ImageAction string
+
+const (
+	ImageActionPull  ImageAction = "pull"
+	ImageActionBuild ImageAction = "build"
+)
+
+var AllImageActions = []struct {
+	Value  ImageAction
+	TSName string
+}{
+	{ImageActionPull, "pull"},
+	{ImageActionBuild, "build"},
+}
+
+// ImageProgress is one message of a pull or build, emitted as ImageProgressEvent
+type ImageProgress struct {
+	Repo   string      `json:"repo"`
+	Action ImageAction `json:"action"`
+	Image  string      `json:"image"`
+	// ID is the layer a pull status is about, Stream a line of build output
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Stream  string `json:"stream"`
+	Current int64  `json:"current"`
+	Total   int64  `json:"total"`
+	Error   string `json:"error,omitempty"`
+}
+
+var ErrNoDockerfile = errors.New("repo has no Dockerfile")
+
+func (this *RepoBrowser) emitImageProgress(repoName string, action ImageAction, imageRef string) dockerclient.ProgressFunc {
+	return func(message jsonmessage.JSONMessage) {
+		progress := ImageProgress{
+			Repo:   repoName,
+			Action: action,
+			Image:  imageRef,
+			ID:     message.ID,
+			Status: message.Status,
+			Stream: message.Stream,
+		}
+		if message.Progress != nil {
+			progress.Current = message.Progress.Current
+			progress.Total = message.Progress.Total
+		}
+		if message.Error != nil {
+			progress.Error = message.Error.Message
+		}
+		runtime.EventsEmit(this.ctx, ImageProgressEvent, progress)
+	}
+}
+
+// repoImageRef finds the image of the repo's container, falling back to an image named after the repo
+func (this *RepoBrowser) repoImageRef(repoName string) string {
+	inspection, err := dockerclient.InspectAnyContainer(this.ctx, repoName)
+	if err != nil {
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to inspect repo container")
+	}
+	if inspection != nil && inspection.Image != "" {
+		return inspection.Image
+	}
+	return repoName
+}
+
+// GetRepoImage returns the ID, tags, creation time and size of the repo's image
+func (this *RepoBrowser) GetRepoImage(repoName string) (dockerclient.ImageInfo, error) {
+	if _, err := this.repos.Get(repoName); err != nil {
+		return dockerclient.ImageInfo{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	info, err := dockerclient.InspectImage(this.ctx, this.repoImageRef(repoName))
+	if err != nil {
+		return dockerclient.ImageInfo{}, fmt.Errorf("failed to get image for repo '%s': %w", repoName, err)
+	}
+	return info, nil
+}
+
+// parseBaseImages reads the images the Dockerfile builds from. Stages built from earlier stages, scratch and images
+// named by build args are left out since there is nothing to pull for them.
+func parseBaseImages(dockerfile string) ([]string, error) {
+	file, err := os.Open(dockerfile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoDockerfile
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Dockerfile: %w", err)
+	}
+	defer file.Close()
+
+	var images []string
+	stages := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		args := slices.DeleteFunc(fields[1:], func(field string) bool {
+			return strings.HasPrefix(field, "--")
+		})
+		if len(args) == 0 {
+			continue
+		}
+		image := args[0]
+		if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+			stages[strings.ToLower(args[2])] = true
+		}
+		if image == "scratch" || stages[strings.ToLower(image)] || strings.Contains(image, "$") || slices.Contains(images, image) {
+			continue
+		}
+		images = append(images, image)
+	}
+	return images, scanner.Err()
+}
+
+// PullRepoBaseImages pulls the latest versions of the images the repo's Dockerfile builds from and returns them.
+// Progress is emitted as ImageProgressEvent.
+func (this *RepoBrowser) PullRepoBaseImages(repoName string) ([]string, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	images, err := parseBaseImages(filepath.Join(repoController.GetBasicDetails().Path, dockerfileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base images of repo '%s': %w", repoName, err)
+	}
+	for _, image := range images {
+		err := dockerclient.PullImage(this.ctx, image, this.emitImageProgress(repoName, ImageActionPull, image))
+		if err != nil {
+			slog.With(slog.Any("error", err), slog.String("image", image)).ErrorContext(this.ctx, "Failed to pull base image")
+			return nil, err
+		}
+	}
+	return images, nil
+}
+
+// RebuildRepoImage builds the repo's image without the build cache, keeping the tags of the current image. It builds
+// the way the repo's compose file does, with its context, Dockerfile, build args and target, and falls back to the
+// Dockerfile at the repo's root. Progress is emitted as ImageProgressEvent.
+func (this *RepoBrowser) RebuildRepoImage(repoName string) (dockerclient.ImageInfo, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return dockerclient.ImageInfo{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	dir := repoController.GetBasicDetails().Path
+	opts, found, err := repo.ComposeBuildOptions(dir, repoName)
+	if err != nil {
+		slog.With(slog.Any("error", err), slog.String("repo", repoName)).WarnContext(this.ctx, "Failed to read build from compose file")
+	}
+	if !found {
+		opts = dockerclient.BuildOptions{Dir: dir, Dockerfile: dockerfileName}
+	}
+	if _, err := os.Stat(filepath.Join(opts.Dir, opts.Dockerfile)); err != nil {
+		return dockerclient.ImageInfo{}, fmt.Errorf("%w: '%s'", ErrNoDockerfile, repoName)
+	}
+	tags := []string{repoName + ":latest"}
+	current, err := dockerclient.InspectImage(this.ctx, this.repoImageRef(repoName))
+	if err == nil && len(current.Tags) > 0 {
+		tags = current.Tags
+	}
+
+	opts.Tags = tags
+	opts.Labels = map[string]string{repoImageLabel: repoName}
+	opts.NoCache = true
+	err = dockerclient.BuildImage(this.ctx, opts, this.emitImageProgress(repoName, ImageActionBuild, tags[0]))
+	if err != nil {
+		slog.With(slog.Any("error", err), slog.String("repo", repoName)).ErrorContext(this.ctx, "Failed to rebuild image")
+		return dockerclient.ImageInfo{}, err
+	}
+	return dockerclient.InspectImage(this.ctx, tags[0])
+}
+
+// PruneRepoImages removes the dangling images left behind by rebuilding repo images
+func (this *RepoBrowser) PruneRepoImages() (dockerclient.PruneReport, error) {
+	report, err := dockerclient.PruneDanglingImages(this.ctx, repoImageLabel)
+	if err != nil {
+		return dockerclient.PruneReport{}, err
+	}
+	slog.With(slog.Int("images", report.ImagesDeleted), slog.Uint64("bytes", report.SpaceReclaimed)).InfoContext(this.ctx, "Pruned repo images")
+	return report, nil
+}
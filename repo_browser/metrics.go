@@ -0,0 +1,105 @@
+package repobrowser
+
+import (
+	"log/slog"
+	"phaas-localservices-ui/dockerclient"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	RepoMetricsEvent = "repo-metrics"
+
+	metricsJobName = "repo-metrics"
+	metricsPeriod  = 5 * time.Second
+	// docker streams stats about once a second, so this keeps roughly the last five minutes
+	metricsHistorySize = 300
+)
+
+type RepoMetrics struct {
+	Repo string `json:"repo"`
+	// API and MySQL are nil while the container isn't running
+	API   *dockerclient.ContainerMetrics `json:"api"`
+	MySQL *dockerclient.ContainerMetrics `json:"mysql"`
+}
+
+type RepoMetricsHistory struct {
+	Repo  string                          `json:"repo"`
+	API   []dockerclient.ContainerMetrics `json:"api"`
+	MySQL []dockerclient.ContainerMetrics `json:"mysql"`
+}
+
+func mysqlContainerName(repoName string) string {
+	return repoName + mysqlContainerSuffix
+}
+
+// startMetrics keeps the stats streams in line with the known repos and emits the current metrics of running repos
+func (this *RepoBrowser) startMetrics() {
+	err := this.jobScheduler.AddJob(metricsJobName, metricsPeriod, this.collectMetrics)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "Failed to add metrics job")
+	}
+}
+
+func (this *RepoBrowser) collectMetrics() {
+	if !this.docker.IsDockerAvailable() {
+		return
+	}
+	var containerNames []string
+	for name := range this.repos.List() {
+		containerNames = append(containerNames, name, mysqlContainerName(name))
+	}
+	err := this.stats.Sync(this.ctx, containerNames)
+	if err != nil {
+		slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to sync container stats")
+		return
+	}
+	metrics := this.ListRepoMetrics()
+	if len(metrics) > 0 {
+		runtime.EventsEmit(this.ctx, RepoMetricsEvent, metrics)
+	}
+}
+
+func (this *RepoBrowser) repoMetrics(repoName string) RepoMetrics {
+	metrics := RepoMetrics{Repo: repoName}
+	if current, ok := this.stats.Current(repoName); ok {
+		metrics.API = &current
+	}
+	if current, ok := this.stats.Current(mysqlContainerName(repoName)); ok {
+		metrics.MySQL = &current
+	}
+	return metrics
+}
+
+// ListRepoMetrics returns the current resource usage of every repo with a running container
+func (this *RepoBrowser) ListRepoMetrics() []RepoMetrics {
+	list := []RepoMetrics{}
+	for name := range this.repos.List() {
+		metrics := this.repoMetrics(name)
+		if metrics.API != nil || metrics.MySQL != nil {
+			list = append(list, metrics)
+		}
+	}
+	return list
+}
+
+// GetRepoMetrics returns the current resource usage of the repo's API and mysql containers
+func (this *RepoBrowser) GetRepoMetrics(repoName string) (RepoMetrics, error) {
+	if _, err := this.repos.Get(repoName); err != nil {
+		return RepoMetrics{}, err
+	}
+	return this.repoMetrics(repoName), nil
+}
+
+// GetRepoMetricsHistory returns the recent resource usage of the repo's API and mysql containers, oldest first
+func (this *RepoBrowser) GetRepoMetricsHistory(repoName string) (RepoMetricsHistory, error) {
+	if _, err := this.repos.Get(repoName); err != nil {
+		return RepoMetricsHistory{}, err
+	}
+	return RepoMetricsHistory{
+		Repo:  repoName,
+		API:   this.stats.History(repoName),
+		MySQL: this.stats.History(mysqlContainerName(repoName)),
+	}, nil
+}
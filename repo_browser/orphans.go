@@ -0,0 +1,218 @@
+package repobrowser
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"phaas-localservices-ui/dockerclient"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+const (
+	serviceNamePrefix    = "phaas-"
+	composeProjectLabel  = "com.docker.compose.project"
+	mysqlContainerSuffix = "-mysql"
+)
+
+// ErrNoReposLoaded refuses a cleanup while no repos are loaded, since every service would then look orphaned
+var ErrNoReposLoaded = errors.New("no repos are loaded")
+
+type OrphanKind string
+
+const (
+	OrphanContainer OrphanKind = "container"
+	OrphanVolume    OrphanKind = "volume"
+)
+
+var AllOrphanKinds = []struct {
+	Value  OrphanKind
+	TSName string
+}{
+	{OrphanContainer, "container"},
+	{OrphanVolume, "volume"},
+}
+
+type Orphan struct {
+	Kind OrphanKind `json:"kind"`
+	// ID is the container ID, or the volume name
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+	SizeBytes int64  `json:"sizeBytes"`
+	// Running containers are reported but never removed
+	Running bool `json:"running"`
+	// Removed and Error are only set by a cleanup that wasn't a dry run
+	Removed bool   `json:"removed"`
+	Error   string `json:"error,omitempty"`
+}
+
+type OrphanReport struct {
+	DryRun  bool     `json:"dryRun"`
+	Orphans []Orphan `json:"orphans"`
+	// ReclaimableBytes is the size of everything found, ReclaimedBytes of what was actually removed
+	ReclaimableBytes int64 `json:"reclaimableBytes"`
+	ReclaimedBytes   int64 `json:"reclaimedBytes"`
+}
+
+// looksLikeService tells whether a container or volume was created for a PHaaS service, by its name or the compose
+// project it belongs to
+func looksLikeService(name string, labels map[string]string) bool {
+	return strings.HasPrefix(name, serviceNamePrefix) || strings.HasPrefix(labels[composeProjectLabel], serviceNamePrefix)
+}
+
+// belongsToRepo tells whether a volume or container belongs to one of the repos, by its compose project or by a name
+// that is the repo's or starts with it, e.g. "phaas-billing-api_mysql-data"
+func belongsToRepo(name string, labels map[string]string, repoNames []string) bool {
+	project := labels[composeProjectLabel]
+	for _, repoName := range repoNames {
+		if project == repoName || project == repoName+mysqlContainerSuffix {
+			return true
+		}
+		if name == repoName || strings.HasPrefix(name, repoName+"_") || strings.HasPrefix(name, repoName+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// containerOrphanReason says why a service container is an orphan, or returns "" when it should be kept. Containers
+// of any repo in the repos dir are kept, except stopped API containers left from old runs. Stopped mysql containers
+// are kept since they may hold the repo's data.
+func containerOrphanReason(name string, running bool, repoDirNames []string, apiRepoNames []string) string {
+	repoName, isMysql := strings.CutSuffix(name, mysqlContainerSuffix)
+	knownRepo := slices.Contains(repoDirNames, repoName)
+	switch {
+	case !knownRepo && !running:
+		return "no matching repo"
+	case !knownRepo:
+		return "no matching repo, stop it first to remove it"
+	case !running && !isMysql && slices.Contains(apiRepoNames, repoName):
+		return "stopped container from an old run"
+	}
+	return ""
+}
+
+// volumeIsOrphan tells whether a service volume can be removed. Volumes of any repo in the repos dir are kept even when
+// no container uses them, e.g. the mysql data after a docker compose down.
+func volumeIsOrphan(name string, labels map[string]string, refCount int64, repoDirNames []string) bool {
+	return refCount == 0 && looksLikeService(name, labels) && !belongsToRepo(name, labels, repoDirNames)
+}
+
+// listRepoDirNames returns the names of every directory in the repos dir. Only API repos get a controller, but the
+// containers and volumes of the others, e.g. UI repos, belong to a repo just as well.
+func listRepoDirNames(reposDirPath string) ([]string, error) {
+	entries, err := os.ReadDir(reposDirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repos dir: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// FindOrphans lists service containers and volumes that are safe to remove: containers of repos that no longer
+// exist, stopped API containers left from old runs, and unused volumes of repos that no longer exist. A repo exists
+// while its directory is in the repos dir. It refuses to run while no repos are loaded, e.g. when the settings are
+// degraded.
+func (this *RepoBrowser) FindOrphans() (OrphanReport, error) {
+	var apiRepoNames []string
+	for name := range this.repos.List() {
+		apiRepoNames = append(apiRepoNames, name)
+	}
+	if len(apiRepoNames) == 0 {
+		return OrphanReport{}, ErrNoReposLoaded
+	}
+	repoDirNames, err := listRepoDirNames(this.settings.ReposDirPath)
+	if err != nil {
+		return OrphanReport{}, err
+	}
+	containers, err := dockerclient.ListAllContainers(this.ctx)
+	if err != nil {
+		return OrphanReport{}, err
+	}
+	usage, err := dockerclient.GetDiskUsage(this.ctx)
+	if err != nil {
+		return OrphanReport{}, err
+	}
+	return findOrphans(containers, usage, repoDirNames, apiRepoNames), nil
+}
+
+func findOrphans(containers []container.Summary, usage dockerclient.DiskUsage, repoDirNames []string, apiRepoNames []string) OrphanReport {
+	report := OrphanReport{DryRun: true, Orphans: []Orphan{}}
+	for _, c := range containers {
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		if !looksLikeService(name, c.Labels) {
+			continue
+		}
+		running := c.State != "exited" && c.State != "created" && c.State != "dead"
+		reason := containerOrphanReason(name, running, repoDirNames, apiRepoNames)
+		if reason == "" {
+			continue
+		}
+		orphan := Orphan{Kind: OrphanContainer, ID: c.ID, Name: name, Reason: reason, SizeBytes: usage.ContainerBytes[c.ID], Running: running}
+		report.Orphans = append(report.Orphans, orphan)
+		if !running {
+			report.ReclaimableBytes += orphan.SizeBytes
+		}
+	}
+
+	for _, v := range usage.Volumes {
+		if v.UsageData == nil || !volumeIsOrphan(v.Name, v.Labels, v.UsageData.RefCount, repoDirNames) {
+			continue
+		}
+		orphan := Orphan{Kind: OrphanVolume, ID: v.Name, Name: v.Name, Reason: "not used by any container"}
+		orphan.SizeBytes = max(v.UsageData.Size, 0)
+		report.Orphans = append(report.Orphans, orphan)
+		report.ReclaimableBytes += orphan.SizeBytes
+	}
+	return report
+}
+
+// CleanupOrphans removes what FindOrphans finds. Running containers are never removed. With dryRun set nothing is
+// removed and the report lists what would be.
+func (this *RepoBrowser) CleanupOrphans(dryRun bool) (OrphanReport, error) {
+	report, err := this.FindOrphans()
+	if err != nil {
+		return OrphanReport{}, fmt.Errorf("failed to find orphans: %w", err)
+	}
+	report.DryRun = dryRun
+	if dryRun {
+		return report, nil
+	}
+
+	// containers first, so volumes they held are free to remove
+	for _, kind := range []OrphanKind{OrphanContainer, OrphanVolume} {
+		for i := range report.Orphans {
+			orphan := &report.Orphans[i]
+			if orphan.Kind != kind || orphan.Running {
+				continue
+			}
+			switch kind {
+			case OrphanContainer:
+				err = dockerclient.RemoveContainer(this.ctx, orphan.ID, true)
+			case OrphanVolume:
+				err = dockerclient.RemoveVolume(this.ctx, orphan.ID)
+			}
+			if err != nil {
+				slog.With(slog.Any("error", err), slog.String("name", orphan.Name)).WarnContext(this.ctx, "Failed to remove orphan")
+				orphan.Error = err.Error()
+				continue
+			}
+			orphan.Removed = true
+			report.ReclaimedBytes += orphan.SizeBytes
+		}
+	}
+	slog.With(slog.Int64("reclaimedBytes", report.ReclaimedBytes)).InfoContext(this.ctx, "Cleaned up orphans")
+	return report, nil
+}
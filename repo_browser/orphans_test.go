@@ -0,0 +1,112 @@
+package repobrowser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"phaas-localservices-ui/dockerclient"
+	"slices"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// apiRepos are the repos with a controller, repoDirs every repo in the repos dir
+var (
+	apiRepos = []string{"phaas-billing-api", "phaas-users-api"}
+	repoDirs = []string{"phaas-billing-api", "phaas-users-api", "phaas-web-ui"}
+)
+
+func TestContainerOrphanReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		running bool
+		want    string
+	}{
+		{"phaas-billing-api", true, ""},
+		{"phaas-billing-api", false, "stopped container from an old run"},
+		{"phaas-billing-api-mysql", false, ""},
+		{"phaas-billing-api-mysql", true, ""},
+		{"phaas-removed-api", false, "no matching repo"},
+		{"phaas-removed-api-mysql", false, "no matching repo"},
+		{"phaas-removed-api", true, "no matching repo, stop it first to remove it"},
+		{"phaas-web-ui", false, ""},
+		{"phaas-web-ui", true, ""},
+	}
+	for _, test := range tests {
+		if got := containerOrphanReason(test.name, test.running, repoDirs, apiRepos); got != test.want {
+			t.Errorf("containerOrphanReason(%q, %t) = %q, want %q", test.name, test.running, got, test.want)
+		}
+	}
+}
+
+func TestVolumeIsOrphan(t *testing.T) {
+	tests := []struct {
+		desc     string
+		name     string
+		labels   map[string]string
+		refCount int64
+		want     bool
+	}{
+		{"unused volume of a removed repo", "phaas-removed-api_mysql-data", nil, 0, true},
+		{"volume still in use", "phaas-removed-api_mysql-data", nil, 1, false},
+		{"mysql data of a known repo", "phaas-billing-api_mysql-data", nil, 0, false},
+		{"known repo by compose project", "3f9a1c", map[string]string{composeProjectLabel: "phaas-users-api"}, 0, false},
+		{"known repo's mysql project", "3f9a1c", map[string]string{composeProjectLabel: "phaas-users-api-mysql"}, 0, false},
+		{"removed repo by compose project", "3f9a1c", map[string]string{composeProjectLabel: "phaas-removed-api"}, 0, true},
+		{"not a service volume", "postgres-data", nil, 0, false},
+		{"volume of a repo without a controller", "phaas-web-ui_node-modules", nil, 0, false},
+	}
+	for _, test := range tests {
+		if got := volumeIsOrphan(test.name, test.labels, test.refCount, repoDirs); got != test.want {
+			t.Errorf("%s: volumeIsOrphan(%q) = %t, want %t", test.desc, test.name, got, test.want)
+		}
+	}
+}
+
+func TestFindOrphansRefusesWithoutRepos(t *testing.T) {
+	browser := &RepoBrowser{}
+	_, err := browser.FindOrphans()
+	if !errors.Is(err, ErrNoReposLoaded) {
+		t.Errorf("FindOrphans() error = %v, want ErrNoReposLoaded", err)
+	}
+}
+
+func TestFindOrphansKeepsUIRepoVolumes(t *testing.T) {
+	reposDir := t.TempDir()
+	for _, name := range []string{"phaas-billing-api", "phaas-web-ui"} {
+		if err := os.Mkdir(filepath.Join(reposDir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(reposDir, "phaas-notes"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dirNames, err := listRepoDirNames(reposDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unused := &volume.UsageData{RefCount: 0, Size: 100}
+	usage := dockerclient.DiskUsage{Volumes: []*volume.Volume{
+		{Name: "phaas-web-ui_node-modules", UsageData: unused},
+		{Name: "phaas-billing-api_mysql-data", UsageData: unused},
+		{Name: "phaas-notes_data", UsageData: unused},
+	}}
+	containers := []container.Summary{
+		{ID: "1", Names: []string{"/phaas-web-ui"}, State: "exited"},
+	}
+
+	report := findOrphans(containers, usage, dirNames, []string{"phaas-billing-api"})
+	var names []string
+	for _, orphan := range report.Orphans {
+		names = append(names, orphan.Name)
+	}
+	if want := []string{"phaas-notes_data"}; !slices.Equal(names, want) {
+		t.Errorf("orphans = %v, want %v", names, want)
+	}
+	if report.ReclaimableBytes != 100 {
+		t.Errorf("ReclaimableBytes = %d, want 100", report.ReclaimableBytes)
+	}
+}
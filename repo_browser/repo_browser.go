@@ -5,39 +5,60 @@ import (
 	"fmt"
 	"iter"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"phaas-localservices-ui/app"
+	"phaas-localservices-ui/dockerclient"
+	"phaas-localservices-ui/history"
+	"phaas-localservices-ui/logquery"
 	"phaas-localservices-ui/repo"
 	"phaas-localservices-ui/scheduler"
 	"slices"
 	"strings"
+	"sync"
 )
 
+// RepoStore is read from bound methods, scheduler jobs and settings change listeners concurrently, so every access
+// goes through its mutex
 type RepoStore struct {
+	mutex           sync.RWMutex
 	repoControllers map[string]repo.Controller
 }
 
 func (this *RepoStore) Push(name string, controller repo.Controller) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
 	if this.repoControllers == nil {
 		this.repoControllers = map[string]repo.Controller{}
 	}
 	this.repoControllers[name] = controller
 }
 
+// List iterates over a snapshot of the controllers, so the store can change while the caller is iterating
 func (this *RepoStore) List() iter.Seq2[string, repo.Controller] {
-	return func(yield func(string, repo.Controller) bool) {
-		for name, r := range this.repoControllers {
-			if !yield(name, r) {
-				return
-			}
-		}
+	this.mutex.RLock()
+	snapshot := maps.Clone(this.repoControllers)
+	this.mutex.RUnlock()
+	return maps.All(snapshot)
+}
+
+// Clear closes and drops every controller
+func (this *RepoStore) Clear() {
+	this.mutex.Lock()
+	repoControllers := this.repoControllers
+	this.repoControllers = nil
+	this.mutex.Unlock()
+	for _, repoController := range repoControllers {
+		repoController.Close()
 	}
 }
 
 var ErrRepoNotFound = fmt.Errorf("repo not found")
 
 func (this *RepoStore) Get(name string) (repo.Controller, error) {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
 	repoController, found := this.repoControllers[name]
 	if !found || repoController == nil {
 		return nil, ErrRepoNotFound
@@ -51,28 +72,78 @@ type RepoBrowser struct {
 
 	jobScheduler          *scheduler.Scheduler
 	repoControllerFactory *repo.Factory
+	history               *history.Store
+	stats                 *dockerclient.StatsCollector
+	docker                *dockerclient.Monitor
+	terminals             terminalStore
+	execs                 execStore
 
 	repos RepoStore
+	// reposMutex serializes loading and rebuilding the repo list, which happens on startup, from the frontend and on
+	// settings changes
+	reposMutex  sync.Mutex
+	reposLoaded bool
 }
 
 func NewRepoBrowser(
 	appSettings *app.Settings,
 	jobScheduler *scheduler.Scheduler,
 	repoControllerFactory *repo.Factory,
+	historyStore *history.Store,
+	dockerMonitor *dockerclient.Monitor,
 ) *RepoBrowser {
-	return &RepoBrowser{
+	browser := &RepoBrowser{
 		settings:              appSettings,
 		jobScheduler:          jobScheduler,
 		repos:                 RepoStore{},
 		repoControllerFactory: repoControllerFactory,
+		history:               historyStore,
+		stats:                 dockerclient.NewStatsCollector(metricsHistorySize),
+		docker:                dockerMonitor,
 	}
+	app.AddChangeListener(appSettings, browser.onSettingsChanged)
+	dockerclient.AddAvailabilityListener(dockerMonitor, browser.onDockerAvailabilityChanged)
+	return browser
 }
 
 func (this *RepoBrowser) Startup(ctx context.Context) {
 	this.ctx = ctx
+	this.startMetrics()
+	if this.settings.GetSettingsStatus().Degraded {
+		slog.WarnContext(ctx, "Settings need fixing, not loading repos")
+		return
+	}
 	err := this.InitRepos()
 	if err != nil {
-		panic(fmt.Errorf("failed to init repos: %w", err))
+		slog.With(slog.Any("error", err)).ErrorContext(ctx, "failed to init repos")
+	}
+}
+
+// onSettingsChanged rebuilds the repo list when the repos dir moves, or when the settings just became usable after
+// the app started degraded. Everything else the controllers need is read from the settings on use.
+func (this *RepoBrowser) onSettingsChanged(change app.SettingsChange) {
+	if this.settings.GetSettingsStatus().Degraded {
+		return
+	}
+	this.reposMutex.Lock()
+	defer this.reposMutex.Unlock()
+	if !change.Has(app.FieldReposDirPath) && this.reposLoaded {
+		return
+	}
+	slog.InfoContext(this.ctx, "Rebuilding repos after settings change")
+	this.repos.Clear()
+	this.reposLoaded = false
+	err := this.initRepos()
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "failed to init repos")
+	}
+}
+
+// onDockerAvailabilityChanged marks every repo's status unknown while docker is down and reconciles them all once
+// it's back, since services may have stopped or been started from the command line in the meantime
+func (this *RepoBrowser) onDockerAvailabilityChanged(available bool) {
+	for _, repoController := range this.repos.List() {
+		repoController.DockerAvailabilityChanged(available)
 	}
 }
 
@@ -81,6 +152,12 @@ type ListReposOptions struct {
 }
 
 func (this *RepoBrowser) InitRepos() error {
+	this.reposMutex.Lock()
+	defer this.reposMutex.Unlock()
+	return this.initRepos()
+}
+
+func (this *RepoBrowser) initRepos() error {
 	if this.settings.ReposDirPath == "" {
 		return nil
 	}
@@ -95,12 +172,16 @@ func (this *RepoBrowser) InitRepos() error {
 			continue
 		}
 		repoName := folder.Name()
+		if _, err := this.repos.Get(repoName); err == nil {
+			continue
+		}
 		path := filepath.Join(this.settings.ReposDirPath, repoName)
 		repoController := this.repoControllerFactory.BuildRepoController(this.ctx, path, repoName, folder)
 		if repoController != nil {
 			this.repos.Push(repoName, repoController)
 		}
 	}
+	this.reposLoaded = true
 	return nil
 }
 
@@ -123,6 +204,9 @@ func (this *RepoBrowser) GetRepoStatus(repoName string) (repo.Status, error) {
 		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "failed to get repo")
 		return repo.Status{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
 	}
+	if !this.docker.IsDockerAvailable() {
+		return repo.Status{State: repo.StateUnknown}, nil
+	}
 	status, err := repoController.GetStatus()
 	if err != nil {
 		return repo.Status{}, fmt.Errorf("failed to get repo status: %w", err)
@@ -135,19 +219,46 @@ func (this *RepoBrowser) StartRepo(repoName string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get repo '%s': %w", repoName, err)
 	}
-	err = repoController.Start()
+	err = repoController.Start(repo.StartOptions{Trigger: history.TriggerUser})
 	if err != nil {
 		return fmt.Errorf("failed to start repo '%s': %w", repoName, err)
 	}
 	return nil
 }
 
+// StartRepoWithEnv starts the repo with one-off env params layered over the global and repo env params
+func (this *RepoBrowser) StartRepoWithEnv(repoName string, envParams []app.EnvParam) error {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	err = repoController.Start(repo.StartOptions{Trigger: history.TriggerUser, Env: envParams})
+	if err != nil {
+		return fmt.Errorf("failed to start repo '%s': %w", repoName, err)
+	}
+	return nil
+}
+
+// GetRepoEffectiveEnv returns the env params a start of the repo would use and the layer each value came from
+func (this *RepoBrowser) GetRepoEffectiveEnv(repoName string) ([]app.ResolvedEnvParam, error) {
+	_, err := this.repos.Get(repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	resolved, err := this.settings.ResolveEnv(repoName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve env for repo '%s': %w", repoName, err)
+	}
+	return resolved, nil
+}
+
 func (this *RepoBrowser) StopRepo(repoName string) error {
 	repoController, err := this.repos.Get(repoName)
 	if err != nil {
 		return fmt.Errorf("failed to get repo '%s': %w", repoName, err)
 	}
-	err = repoController.Stop()
+	this.closeRepoTerminals(repoName)
+	err = repoController.Stop(repo.StopOptions{Trigger: history.TriggerUser})
 	if err != nil {
 		return fmt.Errorf("failed to stop repo '%s': %w", repoName, err)
 	}
@@ -169,3 +280,56 @@ func (this *RepoBrowser) RegisterRepoStatusWatcher(repoName string) error {
 	}
 	return repoController.RegisterStatusWatcher()
 }
+
+func (this *RepoBrowser) GetRepoHistory(repoName string, limit int) ([]history.Entry, error) {
+	_, err := this.repos.Get(repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	entries, err := this.history.List(repoName, limit)
+	if err != nil {
+		slog.With(slog.Any("error", err)).ErrorContext(this.ctx, "failed to list repo history")
+		return nil, fmt.Errorf("failed to list history for repo '%s': %w", repoName, err)
+	}
+	return entries, nil
+}
+
+// ListRepoLogs lists the service logs kept for past runs of the repo, newest first
+func (this *RepoBrowser) ListRepoLogs(repoName string) ([]repo.LogFile, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	logs, err := repoController.ListLogs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list logs for repo '%s': %w", repoName, err)
+	}
+	return logs, nil
+}
+
+// ReadRepoLog reads a chunk of one of the repo's service logs. An empty logName reads the current run's log.
+func (this *RepoBrowser) ReadRepoLog(repoName string, logName string, offset int64, limit int) (repo.LogChunk, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return repo.LogChunk{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	chunk, err := repoController.ReadLog(logName, offset, limit)
+	if err != nil {
+		return repo.LogChunk{}, fmt.Errorf("failed to read log for repo '%s': %w", repoName, err)
+	}
+	return chunk, nil
+}
+
+// QueryRepoLogs searches one of the repo's service logs by text, level and time range and returns a page of the
+// matching lines. An empty logName searches the current run's log.
+func (this *RepoBrowser) QueryRepoLogs(repoName string, logName string, query logquery.Query) (logquery.Result, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return logquery.Result{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	result, err := repoController.QueryLog(logName, query)
+	if err != nil {
+		return logquery.Result{}, fmt.Errorf("failed to query log for repo '%s': %w", repoName, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package repobrowser
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"phaas-localservices-ui/history"
+	"phaas-localservices-ui/repo"
+)
+
+// CreateRepoSnapshot captures the repo's database in a named snapshot. An empty name uses a timestamped one.
+func (this *RepoBrowser) CreateRepoSnapshot(repoName string, name string) (repo.DumpFile, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return repo.DumpFile{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	snapshot, err := repoController.CreateSnapshot(name)
+	if err != nil {
+		return repo.DumpFile{}, fmt.Errorf("failed to snapshot database for repo '%s': %w", repoName, err)
+	}
+	return snapshot, nil
+}
+
+func (this *RepoBrowser) ListRepoSnapshots(repoName string) ([]repo.DumpFile, error) {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	snapshots, err := repoController.ListSnapshots()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots for repo '%s': %w", repoName, err)
+	}
+	return snapshots, nil
+}
+
+func (this *RepoBrowser) DeleteRepoSnapshot(repoName string, name string) error {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	err = repoController.DeleteSnapshot(name)
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot for repo '%s': %w", repoName, err)
+	}
+	return nil
+}
+
+// RestoreRepoSnapshot loads a snapshot into the repo's database. A running API is stopped first and started again
+// afterwards, even when the restore fails.
+func (this *RepoBrowser) RestoreRepoSnapshot(repoName string, name string) error {
+	repoController, err := this.repos.Get(repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	return this.restoreWithAPIStopped(repoController, history.TriggerSnapshotRestore, func() error {
+		err := repoController.RestoreSnapshot(name)
+		if err != nil {
+			slog.With(slog.Any("error", err), slog.String("repo", repoName)).ErrorContext(this.ctx, "Failed to restore snapshot")
+			return fmt.Errorf("failed to restore snapshot for repo '%s': %w", repoName, err)
+		}
+		return nil
+	})
+}
+
+// restoreWithAPIStopped runs restore with the repo's API stopped, so it can't write to the database while it is
+// replaced. An API that was running is started again afterwards, even when the restore fails.
+func (this *RepoBrowser) restoreWithAPIStopped(repoController repo.Controller, trigger history.Trigger, restore func() error) error {
+	repoName := repoController.GetBasicDetails().Name
+	status, err := repoController.GetStatus()
+	if err != nil {
+		return fmt.Errorf("failed to get status of repo '%s': %w", repoName, err)
+	}
+	wasRunning := status.State == repo.StateRunning || status.State == repo.StateStarting
+	if wasRunning {
+		err = this.stopAndWait(repoController, trigger)
+		if err != nil {
+			return err
+		}
+	}
+
+	restoreErr := restore()
+	if wasRunning {
+		err = repoController.Start(restartOptions(repoController, trigger))
+		if err != nil {
+			err = fmt.Errorf("failed to start repo '%s' after restore: %w", repoName, err)
+		}
+	}
+	return errors.Join(restoreErr, err)
+}
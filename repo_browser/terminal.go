@@ -0,0 +1,283 @@
+package repobrowser
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"phaas-localservices-ui/dockerclient"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+var defaultTerminalCmd = []string{"sh", "-c", "if command -v bash >/dev/null; then exec bash; else exec sh; fi"}
+
+var ErrTerminalNotFound = errors.New("terminal session not found")
+
+// TerminalSession names the events of an open terminal. Output carries base64 encoded TTY output, Input takes
+// keystrokes as a string and Closed carries the command's exit code once it ends.
+type TerminalSession struct {
+	ID          string `json:"id"`
+	OutputEvent string `json:"outputEvent"`
+	InputEvent  string `json:"inputEvent"`
+	ClosedEvent string `json:"closedEvent"`
+}
+
+type terminal struct {
+	TerminalSession
+	repoName       string
+	exec           *dockerclient.ExecSession
+	cancelListener func()
+}
+
+type terminalStore struct {
+	mutex     sync.Mutex
+	terminals map[string]*terminal
+}
+
+func (this *terminalStore) add(t *terminal) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.terminals == nil {
+		this.terminals = map[string]*terminal{}
+	}
+	this.terminals[t.ID] = t
+}
+
+func (this *terminalStore) get(id string) (*terminal, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	t, ok := this.terminals[id]
+	return t, ok
+}
+
+func (this *terminalStore) remove(id string) (*terminal, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	t, ok := this.terminals[id]
+	delete(this.terminals, id)
+	return t, ok
+}
+
+// removeWhere drops and returns the terminals that match
+func (this *terminalStore) removeWhere(matches func(t *terminal) bool) []*terminal {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	var removed []*terminal
+	for id, t := range this.terminals {
+		if matches(t) {
+			removed = append(removed, t)
+			delete(this.terminals, id)
+		}
+	}
+	return removed
+}
+
+func newTerminalID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// OpenRepoTerminal starts an interactive command, a shell by default, in the repo's running container. The TTY is
+// streamed over the events named in the returned session until the command exits or CloseRepoTerminal is called.
+func (this *RepoBrowser) OpenRepoTerminal(repoName string, cmd []string, rows uint, cols uint) (TerminalSession, error) {
+	if _, err := this.repos.Get(repoName); err != nil {
+		return TerminalSession{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	if len(cmd) == 0 {
+		cmd = defaultTerminalCmd
+	}
+	exec, err := dockerclient.OpenExecSession(this.ctx, repoName, cmd, rows, cols)
+	if err != nil {
+		return TerminalSession{}, fmt.Errorf("failed to open terminal for repo '%s': %w", repoName, err)
+	}
+
+	id := newTerminalID()
+	t := &terminal{
+		TerminalSession: TerminalSession{
+			ID:          id,
+			OutputEvent: fmt.Sprintf("terminal-%s-output", id),
+			InputEvent:  fmt.Sprintf("terminal-%s-input", id),
+			ClosedEvent: fmt.Sprintf("terminal-%s-closed", id),
+		},
+		repoName: repoName,
+		exec:     exec,
+	}
+	t.cancelListener = runtime.EventsOn(this.ctx, t.InputEvent, func(data ...any) {
+		for _, d := range data {
+			input, ok := d.(string)
+			if !ok {
+				continue
+			}
+			_, err := exec.Write([]byte(input))
+			if err != nil {
+				slog.With(slog.Any("error", err)).WarnContext(this.ctx, "Failed to write to terminal")
+			}
+		}
+	})
+	this.terminals.add(t)
+	slog.With(slog.String("repo", repoName), slog.String("terminal", id)).InfoContext(this.ctx, "Opened terminal")
+
+	go this.streamTerminal(t)
+	return t.TerminalSession, nil
+}
+
+func (this *RepoBrowser) streamTerminal(t *terminal) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := t.exec.Read(buf)
+		if n > 0 {
+			runtime.EventsEmit(this.ctx, t.OutputEvent, base64.StdEncoding.EncodeToString(buf[:n]))
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				slog.With(slog.Any("error", err)).DebugContext(this.ctx, "Terminal stream ended")
+			}
+			break
+		}
+	}
+	exitCode, err := t.exec.ExitCode()
+	if err != nil {
+		exitCode = -1
+	}
+	this.closeTerminal(t.ID)
+	runtime.EventsEmit(this.ctx, t.ClosedEvent, exitCode)
+}
+
+func (this *RepoBrowser) closeTerminal(id string) bool {
+	t, ok := this.terminals.remove(id)
+	if !ok {
+		return false
+	}
+	t.close()
+	return true
+}
+
+func (this *terminal) close() {
+	this.cancelListener()
+	this.exec.Close()
+}
+
+// closeRepoTerminals closes the terminals open in the repo's container. Their streams then end and report the
+// terminals as closed to the frontend.
+func (this *RepoBrowser) closeRepoTerminals(repoName string) {
+	closed := this.terminals.removeWhere(func(t *terminal) bool {
+		return t.repoName == repoName
+	})
+	for _, t := range closed {
+		t.close()
+	}
+	if len(closed) > 0 {
+		slog.With(slog.String("repo", repoName), slog.Int("closed", len(closed))).InfoContext(this.ctx, "Closed repo terminals")
+	}
+}
+
+// Shutdown closes every open terminal when the app quits, so no exec session is left attached to a container
+func Shutdown(browser *RepoBrowser) {
+	closed := browser.terminals.removeWhere(func(t *terminal) bool {
+		return true
+	})
+	for _, t := range closed {
+		t.close()
+	}
+}
+
+func (this *RepoBrowser) ResizeRepoTerminal(id string, rows uint, cols uint) error {
+	t, ok := this.terminals.get(id)
+	if !ok {
+		return ErrTerminalNotFound
+	}
+	return t.exec.Resize(rows, cols)
+}
+
+func (this *RepoBrowser) CloseRepoTerminal(id string) error {
+	if !this.closeTerminal(id) {
+		return ErrTerminalNotFound
+	}
+	return nil
+}
+
+const (
+	defaultExecTimeout = time.Minute
+	maxExecTimeout     = 30 * time.Minute
+)
+
+// execStore tracks the in-flight execs of each repo so they can be cancelled
+type execStore struct {
+	mutex   sync.Mutex
+	nextID  int
+	cancels map[string]map[int]context.CancelFunc
+}
+
+func (this *execStore) add(repoName string, cancel context.CancelFunc) func() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.cancels == nil {
+		this.cancels = map[string]map[int]context.CancelFunc{}
+	}
+	if this.cancels[repoName] == nil {
+		this.cancels[repoName] = map[int]context.CancelFunc{}
+	}
+	this.nextID++
+	id := this.nextID
+	this.cancels[repoName][id] = cancel
+	return func() {
+		this.mutex.Lock()
+		defer this.mutex.Unlock()
+		delete(this.cancels[repoName], id)
+	}
+}
+
+func (this *execStore) cancelAll(repoName string) int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	cancelled := len(this.cancels[repoName])
+	for _, cancel := range this.cancels[repoName] {
+		cancel()
+	}
+	delete(this.cancels, repoName)
+	return cancelled
+}
+
+// ExecInRepoContainer runs a command in the repo's running container and returns its combined output. A non-zero
+// exit code is reported in the result rather than as an error. The command is abandoned after timeoutSeconds (60 when
+// zero or less, at most 30 minutes) or when CancelRepoExecs is called for the repo.
+func (this *RepoBrowser) ExecInRepoContainer(repoName string, argv []string, timeoutSeconds int) (dockerclient.ExecResult, error) {
+	if _, err := this.repos.Get(repoName); err != nil {
+		return dockerclient.ExecResult{}, fmt.Errorf("failed to get repo '%s': %w", repoName, err)
+	}
+	if len(argv) == 0 {
+		return dockerclient.ExecResult{}, errors.New("no command given")
+	}
+	timeout := defaultExecTimeout
+	if timeoutSeconds > 0 {
+		timeout = min(time.Duration(timeoutSeconds)*time.Second, maxExecTimeout)
+	}
+	ctx, cancel := context.WithTimeout(this.ctx, timeout)
+	defer cancel()
+	done := this.execs.add(repoName, cancel)
+	defer done()
+
+	result, err := dockerclient.ExecCombined(ctx, repoName, argv)
+	if err != nil {
+		return dockerclient.ExecResult{}, fmt.Errorf("failed to exec in repo '%s': %w", repoName, err)
+	}
+	return result, nil
+}
+
+// CancelRepoExecs abandons every command started with ExecInRepoContainer for the repo that is still running and
+// returns how many were cancelled
+func (this *RepoBrowser) CancelRepoExecs(repoName string) int {
+	cancelled := this.execs.cancelAll(repoName)
+	if cancelled > 0 {
+		slog.With(slog.String("repo", repoName), slog.Int("cancelled", cancelled)).InfoContext(this.ctx, "Cancelled repo execs")
+	}
+	return cancelled
+}
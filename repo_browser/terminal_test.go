@@ -0,0 +1,35 @@
+package repobrowser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTerminalStoreRemoveWhere(t *testing.T) {
+	store := &terminalStore{}
+	for _, term := range []*terminal{
+		{TerminalSession: TerminalSession{ID: "a"}, repoName: "phaas-billing-api"},
+		{TerminalSession: TerminalSession{ID: "b"}, repoName: "phaas-users-api"},
+		{TerminalSession: TerminalSession{ID: "c"}, repoName: "phaas-billing-api"},
+	} {
+		store.add(term)
+	}
+
+	removed := store.removeWhere(func(term *terminal) bool {
+		return term.repoName == "phaas-billing-api"
+	})
+	ids := make([]string, len(removed))
+	for i, term := range removed {
+		ids[i] = term.ID
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"a", "c"}) {
+		t.Errorf("removeWhere() removed %v, want the billing terminals", ids)
+	}
+	if _, ok := store.get("a"); ok {
+		t.Error("a removed terminal is still in the store")
+	}
+	if _, ok := store.get("b"); !ok {
+		t.Error("the other repo's terminal was removed")
+	}
+}
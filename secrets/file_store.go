@@ -0,0 +1,175 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const keySize = 32
+
+// fileStore keeps secrets AES-GCM encrypted in a JSON file, with the key in a separate file readable only by the
+// current user. It only protects against the secrets file itself leaking, e.g. through a dotfiles repo.
+type fileStore struct {
+	mutex       sync.Mutex
+	secretsPath string
+	key         []byte
+}
+
+func newFileStore(dir string) (*fileStore, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+	key, err := loadOrCreateKey(filepath.Join(dir, "secrets.key"))
+	if err != nil {
+		return nil, err
+	}
+	return &fileStore{
+		secretsPath: filepath.Join(dir, "secrets.json"),
+		key:         key,
+	}, nil
+}
+
+func loadOrCreateKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("secrets key file '%s' is corrupt", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read secrets key: %w", err)
+	}
+	key = make([]byte, keySize)
+	_, err = rand.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secrets key: %w", err)
+	}
+	err = os.WriteFile(path, key, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write secrets key: %w", err)
+	}
+	return key, nil
+}
+
+func (this *fileStore) Get(ref string) (string, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	stored, err := this.read()
+	if err != nil {
+		return "", err
+	}
+	encrypted, found := stored[ref]
+	if !found {
+		return "", ErrNotFound
+	}
+	return this.decrypt(encrypted)
+}
+
+func (this *fileStore) Set(ref string, value string) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	stored, err := this.read()
+	if err != nil {
+		return err
+	}
+	encrypted, err := this.encrypt(value)
+	if err != nil {
+		return err
+	}
+	stored[ref] = encrypted
+	return this.write(stored)
+}
+
+func (this *fileStore) Delete(ref string) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	stored, err := this.read()
+	if err != nil {
+		return err
+	}
+	if _, found := stored[ref]; !found {
+		return nil
+	}
+	delete(stored, ref)
+	return this.write(stored)
+}
+
+func (this *fileStore) read() (map[string]string, error) {
+	stored := map[string]string{}
+	data, err := os.ReadFile(this.secretsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return stored, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	err = json.Unmarshal(data, &stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal secrets file: %w", err)
+	}
+	return stored, nil
+}
+
+func (this *fileStore) write(stored map[string]string) error {
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	err = os.WriteFile(this.secretsPath, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	return nil
+}
+
+func (this *fileStore) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(this.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (this *fileStore) encrypt(value string) (string, error) {
+	gcm, err := this.gcm()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (this *fileStore) decrypt(encrypted string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	gcm, err := this.gcm()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("stored secret is corrupt")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	value, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(value), nil
+}
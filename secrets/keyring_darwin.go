@@ -0,0 +1,60 @@
+//go:build darwin
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainStore stores secrets as generic passwords in the login keychain using the security CLI
+type keychainStore struct{}
+
+func newKeyringStore() (Store, error) {
+	_, err := exec.LookPath("security")
+	if err != nil {
+		return nil, fmt.Errorf("%w: security CLI not found: %w", ErrKeyringUnavailable, err)
+	}
+	return keychainStore{}, nil
+}
+
+// errItemNotFound is the exit code security uses when no matching keychain item exists
+const errItemNotFound = 44
+
+func (this keychainStore) Get(ref string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", serviceName, "-a", ref, "-w").Output()
+	if err != nil {
+		if isNotFound(err) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to get secret from keychain: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Set passes the value on stdin rather than as an argument so it doesn't show up in the process list. With -w as the
+// last option security prompts for the password and asks for it a second time to confirm.
+func (this keychainStore) Set(ref string, value string) error {
+	cmd := exec.Command("security", "add-generic-password", "-U", "-s", serviceName, "-a", ref, "-w")
+	cmd.Stdin = strings.NewReader(value + "\n" + value + "\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to store secret in keychain: %w: %s", err, out)
+	}
+	return nil
+}
+
+func (this keychainStore) Delete(ref string) error {
+	out, err := exec.Command("security", "delete-generic-password", "-s", serviceName, "-a", ref).CombinedOutput()
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete secret from keychain: %w: %s", err, out)
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == errItemNotFound
+}
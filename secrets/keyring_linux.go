@@ -0,0 +1,208 @@
+//go:build linux
+
+package secrets
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	secretServiceName     = "org.freedesktop.secrets"
+	secretServicePath     = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretServiceIface    = "org.freedesktop.Secret.Service"
+	secretCollectionPath  = dbus.ObjectPath("/org/freedesktop/secrets/aliases/default")
+	secretCollectionIface = "org.freedesktop.Secret.Collection"
+	secretItemIface       = "org.freedesktop.Secret.Item"
+	secretPromptIface     = "org.freedesktop.Secret.Prompt"
+
+	noPrompt      = dbus.ObjectPath("/")
+	promptTimeout = 2 * time.Minute
+)
+
+// secretServiceSecret mirrors the Secret struct of the Secret Service API
+type secretServiceSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// secretServiceStore stores secrets in the default collection of the freedesktop Secret Service
+// (gnome-keyring, KWallet, KeePassXC, ...)
+type secretServiceStore struct {
+	mutex   sync.Mutex
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+func newKeyringStore() (Store, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to connect to dbus session bus: %w", ErrKeyringUnavailable, err)
+	}
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err = conn.Object(secretServiceName, secretServicePath).
+		Call(secretServiceIface+".OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&output, &session)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open secret service session: %w", ErrKeyringUnavailable, err)
+	}
+	return &secretServiceStore{
+		conn:    conn,
+		session: session,
+	}, nil
+}
+
+func attributes(ref string) map[string]string {
+	return map[string]string{
+		"application": serviceName,
+		"ref":         ref,
+	}
+}
+
+func (this *secretServiceStore) Get(ref string) (string, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	items, err := this.search(ref)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", ErrNotFound
+	}
+	var secret secretServiceSecret
+	err = this.conn.Object(secretServiceName, items[0]).
+		Call(secretItemIface+".GetSecret", 0, this.session).
+		Store(&secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret from keyring: %w", err)
+	}
+	return string(secret.Value), nil
+}
+
+func (this *secretServiceStore) Set(ref string, value string) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	err := this.unlock([]dbus.ObjectPath{secretCollectionPath})
+	if err != nil {
+		return err
+	}
+	properties := map[string]dbus.Variant{
+		secretItemIface + ".Label":      dbus.MakeVariant(fmt.Sprintf("PHaaS local services: %s", ref)),
+		secretItemIface + ".Attributes": dbus.MakeVariant(attributes(ref)),
+	}
+	secret := secretServiceSecret{
+		Session:     this.session,
+		Parameters:  []byte{},
+		Value:       []byte(value),
+		ContentType: "text/plain; charset=utf8",
+	}
+	var item, prompt dbus.ObjectPath
+	err = this.conn.Object(secretServiceName, secretCollectionPath).
+		Call(secretCollectionIface+".CreateItem", 0, properties, secret, true).
+		Store(&item, &prompt)
+	if err != nil {
+		return fmt.Errorf("failed to store secret in keyring: %w", err)
+	}
+	return this.prompt(prompt)
+}
+
+func (this *secretServiceStore) Delete(ref string) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	items, err := this.search(ref)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var prompt dbus.ObjectPath
+		err := this.conn.Object(secretServiceName, item).Call(secretItemIface+".Delete", 0).Store(&prompt)
+		if err != nil {
+			return fmt.Errorf("failed to delete secret from keyring: %w", err)
+		}
+		err = this.prompt(prompt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// search returns the unlocked items matching ref, unlocking any locked ones
+func (this *secretServiceStore) search(ref string) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := this.conn.Object(secretServiceName, secretServicePath).
+		Call(secretServiceIface+".SearchItems", 0, attributes(ref)).
+		Store(&unlocked, &locked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search keyring: %w", err)
+	}
+	if len(locked) > 0 {
+		err = this.unlock(locked)
+		if err != nil {
+			return nil, err
+		}
+		unlocked = append(unlocked, locked...)
+	}
+	return unlocked, nil
+}
+
+func (this *secretServiceStore) unlock(objects []dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := this.conn.Object(secretServiceName, secretServicePath).
+		Call(secretServiceIface+".Unlock", 0, objects).
+		Store(&unlocked, &prompt)
+	if err != nil {
+		return fmt.Errorf("failed to unlock keyring: %w", err)
+	}
+	return this.prompt(prompt)
+}
+
+// prompt shows the keyring's own unlock/confirmation prompt, if it asked for one, and waits for it to complete
+func (this *secretServiceStore) prompt(prompt dbus.ObjectPath) error {
+	if prompt == noPrompt || prompt == "" {
+		return nil
+	}
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(secretPromptIface),
+		dbus.WithMatchMember("Completed"),
+	}
+	err := this.conn.AddMatchSignal(match...)
+	if err != nil {
+		return fmt.Errorf("failed to watch keyring prompt: %w", err)
+	}
+	defer this.conn.RemoveMatchSignal(match...)
+	signals := make(chan *dbus.Signal, 1)
+	this.conn.Signal(signals)
+	defer this.conn.RemoveSignal(signals)
+
+	err = this.conn.Object(secretServiceName, prompt).Call(secretPromptIface+".Prompt", 0, "").Err
+	if err != nil {
+		return fmt.Errorf("failed to show keyring prompt: %w", err)
+	}
+
+	timeout := time.After(promptTimeout)
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path != prompt || signal.Name != secretPromptIface+".Completed" {
+				continue
+			}
+			if len(signal.Body) > 0 {
+				if dismissed, ok := signal.Body[0].(bool); ok && dismissed {
+					return ErrKeyringPromptFailed
+				}
+			}
+			return nil
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for keyring prompt")
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin
+
+package secrets
+
+func newKeyringStore() (Store, error) {
+	return nil, ErrKeyringUnavailable
+}
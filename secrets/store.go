@@ -0,0 +1,33 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+const serviceName = "phaas-localservices-manager"
+
+var (
+	ErrNotFound            = errors.New("secret not found")
+	ErrKeyringUnavailable  = errors.New("system keyring unavailable")
+	ErrKeyringPromptFailed = errors.New("keyring unlock prompt was dismissed")
+)
+
+// Store keeps secret values out of the settings file, addressed by a ref that is unique per secret
+type Store interface {
+	Get(ref string) (string, error)
+	Set(ref string, value string) error
+	Delete(ref string) error
+}
+
+// NewStore returns a store backed by the system keyring, falling back to an encrypted file in fallbackDir when
+// no keyring is available
+func NewStore(ctx context.Context, fallbackDir string) (Store, error) {
+	keyring, err := newKeyringStore()
+	if err == nil {
+		return keyring, nil
+	}
+	slog.With(slog.Any("error", err)).WarnContext(ctx, "System keyring unavailable, storing secrets in encrypted file")
+	return newFileStore(fallbackDir)
+}